@@ -0,0 +1,103 @@
+// Public domain
+
+package astro
+
+// Apparent: Chapter 33, Elliptic Motion, apparent places of planets.
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/unit"
+)
+
+// ApparentPlanet returns the apparent geocentric place of a planet.
+//
+// Argument planet is a V87Planet for the observed body, earth is a
+// V87Planet for Earth.  They must be loaded from versions of VSOP87 with the
+// same frame, or an error is returned.  Versions with J2000 frames are
+// precessed to the ecliptic and equinox of date, versions V87C and V87D are
+// already of date.
+//
+// The procedure is that of Meeus p. 223-225:  Light-time is iterated, the
+// geometric place is corrected for aberration, converted to the FK5
+// system, and corrected for nutation.
+//
+// Results are apparent right ascension α and declination δ for the true
+// equator and equinox of date, and the distance Δ in AU, corrected for
+// light-time.
+func ApparentPlanet(planet, earth *V87Planet, jde float64) (α unit.RA, δ unit.Angle, Δ float64, err error) {
+	if pf, ef := planet.Frame(), earth.Frame(); pf != ef {
+		err = fmt.Errorf("Planet frame %s, earth frame %s.", pf, ef)
+		return
+	}
+	e := earth.Rectangular(jde)
+	var g coord.Cart
+	τ := 0.
+	for i := 0; i < 10; i++ {
+		p := planet.Rectangular(jde - τ)
+		g.Sub(&p, &e)
+		Δ = math.Sqrt(g.Square())
+		τ1 := LightTime * Δ // (33.3) p. 224
+		if math.Abs(τ1-τ) < 1e-12 {
+			break
+		}
+		τ = τ1
+	}
+	// (33.1, 33.2) p. 223
+	λ := unit.Angle(math.Atan2(g.Y, g.X))
+	β := unit.Angle(math.Atan2(g.Z, math.Hypot(g.X, g.Y)))
+	if !planet.version.OfDate() {
+		λ, β = PrecessEcliptic(λ, β, J2000, jde)
+	}
+	Δλ, Δβ := eclipticAberration(λ, β, jde)
+	λ, β = toFK5(λ+Δλ, β+Δβ, jde)
+	Δψ, Δε := Nutation(jde)
+	λ += Δψ
+	sε, cε := (MeanObliquity(jde) + Δε).Sincos()
+	α, δ = eclToEq(λ, β, sε, cε)
+	return
+}
+
+// κ is the constant of aberration.
+var κ = unit.AngleFromSec(20.49552)
+
+// eclipticAberration returns corrections due to annual aberration for
+// ecliptic coordinates of an object.
+func eclipticAberration(λ, β unit.Angle, jde float64) (Δλ, Δβ unit.Angle) {
+	T := J2000Century(jde)
+	s, _ := solarTrue(T)
+	e := earthEccentricity(T)
+	// longitude of perihelion, p. 151
+	π := unit.AngleFromDeg(Horner(T, 102.93735, 1.71946, .00046))
+	sβ, cβ := β.Sincos()
+	ssλ, csλ := (s - λ).Sincos()
+	sπλ, cπλ := (π - λ).Sincos()
+	// (23.2) p. 151
+	Δλ = κ.Mul((e*cπλ - csλ) / cβ)
+	Δβ = -κ.Mul(sβ * (ssλ - e*sπλ))
+	return
+}
+
+// toFK5 converts ecliptic longitude and latitude from the dynamical frame
+// of VSOP87 to FK5.
+func toFK5(L, B unit.Angle, jde float64) (L5, B5 unit.Angle) {
+	T := J2000Century(jde)
+	Lp := L - unit.AngleFromDeg(1.397*T+.00031*T*T)
+	sLp, cLp := Lp.Sincos()
+	// (32.3) p. 219
+	L5 = L + unit.AngleFromSec(-.09033+.03916*(cLp+sLp)*B.Tan())
+	B5 = B + unit.AngleFromSec(.03916*(cLp-sLp))
+	return
+}
+
+// eclToEq converts ecliptic coordinates to equatorial coordinates, given
+// sine and cosine of the obliquity.
+func eclToEq(λ, β unit.Angle, sε, cε float64) (α unit.RA, δ unit.Angle) {
+	sλ, cλ := λ.Sincos()
+	sβ, cβ := β.Sincos()
+	α = unit.RAFromRad(math.Atan2(sλ*cε-(sβ/cβ)*sε, cλ)) // (13.3) p. 93
+	δ = unit.Angle(math.Asin(sβ*cε + cβ*sε*sλ))          // (13.4) p. 93
+	return
+}
@@ -0,0 +1,52 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/sexagesimal"
+)
+
+func TestApparentPlanetMeeus(t *testing.T) {
+	// Example 33.a, p. 225.  VSOP87 result p. 227.
+	earth := loadPlanet(t, astro.Earth)
+	venus := loadPlanet(t, astro.Venus)
+	α, δ, _, err := astro.ApparentPlanet(venus, earth, 2448976.5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := fmt.Sprintf("%.3d", sexa.FmtRA(α)); s != "21ʰ4ᵐ41ˢ.454" {
+		t.Error("α =", s)
+	}
+	if s := fmt.Sprintf("%.2d", sexa.FmtAngle(δ)); s != "-18°53′16″.84" {
+		t.Error("δ =", s)
+	}
+}
+
+func TestApparentPlanetFrames(t *testing.T) {
+	dir := t.TempDir()
+	writeV87(t, dir, astro.V87A, astro.Earth, [][][][3]float64{
+		{{{1, 0, 6283}}}, {{{1, -math.Pi / 2, 6283}}}, {{{0, 0, 0}}},
+	})
+	writeV87(t, dir, astro.V87C, astro.Venus, [][][][3]float64{
+		{{{.72, 0, 10213}}}, {{{.72, -math.Pi / 2, 10213}}}, {{{0, 0, 0}}},
+	})
+	earth, err := astro.LoadPlanetPathVersion(astro.Earth, astro.V87A, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	venus, err := astro.LoadPlanetPathVersion(astro.Venus, astro.V87C, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := astro.ApparentPlanet(venus, earth, 2448976.5); err == nil {
+		t.Error("J2000 earth with of date planet: expected error")
+	}
+	if _, _, _, err := astro.ApparentPlanet(earth, earth, 2448976.5); err != nil {
+		t.Error(err)
+	}
+}
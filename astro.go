@@ -30,6 +30,16 @@ const (
 	JulianCentury = 36525     // days
 )
 
+// Julian and Besselian years and common epochs, p. 133.
+const (
+	JulianYear    = 365.25      // days
+	BesselianYear = 365.2421988 // days
+
+	J1900 = 2415020.0
+	B1900 = 2415020.3135
+	B1950 = 2433282.4235
+)
+
 // J2000Century returns the number of Julian centuries since J2000.
 //
 // The quantity appears as T in a number of time series.
@@ -44,7 +54,7 @@ func J2000Century(jde float64) float64 {
 //
 // The algorithm becomes unstable for near-parabolic orbits or orbits with
 // large semimajor axes.  The function returns ok=false if a would be > 100 AU
-// or if e would be > .99.
+// or if e would be > .99.  See ElementsFromState for a general solution.
 //
 // Args:
 //   p = position: sun object vector, in AU
@@ -100,27 +110,16 @@ func AeiHv(p, v *coord.Cart, d float64, hv *coord.Cart) (a, e float64, i unit.An
 }
 
 // HMag computes H from V magnitude.
+//
+// The H-G system is used with G = .15.  See HMagG.
 func HMag(oov, sov *coord.Cart, vmag, ood, sod float64) float64 {
-	rdelta := ood * sod
-	cospsi := oov.Dot(sov) / rdelta
-
-	if cospsi < -.9999 {
-		// object is straight into the sun.  doesn't seem too likely,
-		// but anyway, this returns a valid value.
-		return 30
-	}
-
-	tanhalf := math.Sqrt(1-cospsi*cospsi) / (1 + cospsi)
-	phi1 := math.Exp(-3.33 * math.Pow(tanhalf, 0.63))
-	phi2 := math.Exp(-1.87 * math.Pow(tanhalf, 1.22))
-	return vmag -
-		5.*math.Log10(rdelta) +
-		2.5*math.Log10(.85*phi1+.15*phi2)
+	return HMagG(oov, sov, vmag, ood, sod, .15)
 }
 
 // Lst computes (approximate) local sidereal time.
 //
-// Argument mjd is modified Julian day.
+// Argument mjd is modified Julian day.  For precise work see GMST06,
+// GAST06, and LocalSidereal.
 func Lst(mjd float64, long unit.Angle) unit.Time {
 	t := (mjd - 15019.5) / 36525
 	th := (6.6460656 + (2400.051262+0.00002581*t)*t)
@@ -187,7 +186,7 @@ func Horner(x float64, c ...float64) float64 {
 // intermediate values.  This function can be used in many places where INT()
 // appears in AA.  As with built in integer division, it panics with y == 0.
 func floorDiv(x, y int) int {
-	if (x < 0) == (y < 0) {
+	if (x < 0) == (y < 0) || x%y == 0 {
 		return x / y
 	}
 	return x/y - 1
@@ -199,7 +198,7 @@ func floorDiv(x, y int) int {
 // intermediate values.  This function can be used in many places where INT()
 // appears in AA.  As with built in integer division, it panics with y == 0.
 func floorDiv64(x, y int64) int64 {
-	if (x < 0) == (y < 0) {
+	if (x < 0) == (y < 0) || x%y == 0 {
 		return x / y
 	}
 	return x/y - 1
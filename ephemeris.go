@@ -0,0 +1,195 @@
+// Public domain
+
+package astro
+
+// Ephemeris: a common interface to the ephemerides of the package.
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/soniakeys/coord"
+)
+
+// Ephemeris is implemented by sources of position and velocity.
+//
+// State returns rectangular position in AU and velocity in AU/day for
+// Julian ephemeris day jde.  Frame describes the coordinates.
+//
+// Implementations in this package are V87Planet, Orbit, Se2000Ephemeris,
+// SolarJ2000Ephemeris, and SPKEphemeris.
+type Ephemeris interface {
+	State(jde float64) (pos, vel coord.Cart)
+	Frame() Frame
+}
+
+// Origin identifies the origin of coordinates of an Ephemeris.
+type Origin int
+
+// Origins of coordinates.
+const (
+	OriginSun        Origin = iota // heliocentric
+	OriginBarycenter               // solar system barycentric
+	OriginEarth                    // geocentric
+)
+
+// Frame describes the coordinates of an Ephemeris.
+type Frame struct {
+	Origin     Origin
+	Equatorial bool // equatorial if true, ecliptic if false
+	OfDate     bool // mean equinox of date if true, J2000 if false
+}
+
+// String returns a description of the frame such as
+// "heliocentric ecliptic J2000".
+func (f Frame) String() string {
+	s := [...]string{"heliocentric", "barycentric", "geocentric"}[f.Origin]
+	if f.Equatorial {
+		s += " equatorial"
+	} else {
+		s += " ecliptic"
+	}
+	if f.OfDate {
+		return s + " of date"
+	}
+	return s + " J2000"
+}
+
+// Frame returns the frame of the series of vt, as determined by the
+// version loaded.
+//
+// Positions and velocities are by the State method.
+func (vt *V87Planet) Frame() Frame {
+	f := Frame{OfDate: vt.version.OfDate()}
+	if vt.version.Barycentric() {
+		f.Origin = OriginBarycenter
+	}
+	return f
+}
+
+// State returns heliocentric rectangular equatorial position and velocity
+// for the orbit, as PositionVelocity.
+func (o *Orbit) State(jde float64) (pos, vel coord.Cart) {
+	return o.PositionVelocity(jde)
+}
+
+// Frame returns the frame of Orbit states, heliocentric equatorial J2000.
+func (o *Orbit) Frame() Frame {
+	return Frame{Origin: OriginSun, Equatorial: true}
+}
+
+// Se2000Ephemeris is an Ephemeris of the Sun by the approximate solar
+// coordinates of Se2000.
+//
+// States are the sun-earth vector of Se2000, equatorial for the mean
+// equator and equinox of date as the USNO formulas give it.  As Se2000 takes
+// MJD, the difference between TT and UT is neglected.  Velocity is by
+// derivatives of the Se2000 expressions, neglecting the slow change in
+// obliquity.
+type Se2000Ephemeris struct{}
+
+// State returns the position and velocity of Se2000.
+func (Se2000Ephemeris) State(jde float64) (pos, vel coord.Cart) {
+	mjd := jde - 2400000.5
+	pos, soe, coe := Se2000(mjd)
+	const dg = .98560028 * math.Pi / 180 // radians/day
+	d := mjd - 51544.5
+	g := (357.529 + .98560028*d) * math.Pi / 180
+	sg, cg := math.Sincos(g)
+	sg2, cg2 := math.Sincos(2 * g)
+	l := math.Atan2(pos.Y/coe, pos.X)
+	sl, cl := math.Sincos(l)
+	r := math.Hypot(pos.X, pos.Y/coe)
+	ld := .98564736*math.Pi/180 + (1.915*cg+.040*cg2)*math.Pi/180*dg
+	rd := (.01671*sg + .00028*sg2) * dg
+	vel.X = rd*cl - r*sl*ld
+	ved := rd*sl + r*cl*ld
+	vel.Y = ved * coe
+	vel.Z = ved * soe
+	return
+}
+
+// Frame returns the frame of Se2000 states, geocentric equatorial of date.
+func (Se2000Ephemeris) Frame() Frame {
+	return Frame{Origin: OriginEarth, Equatorial: true, OfDate: true}
+}
+
+// SolarJ2000Ephemeris is an Ephemeris of the Sun by full VSOP87 theory
+// as in SolarPositionJ2000.
+//
+// States are the Earth-Sun vector.  Earth must hold series for Earth of a
+// J2000 version of VSOP87; for a version of date State returns NaN
+// coordinates.
+type SolarJ2000Ephemeris struct {
+	Earth *V87Planet
+}
+
+// State returns the geocentric position and velocity of the Sun.
+func (s SolarJ2000Ephemeris) State(jde float64) (pos, vel coord.Cart) {
+	pos, vel, err := s.Earth.StateJ2000(jde)
+	if err != nil {
+		return nanState()
+	}
+	pos.Neg(&pos)
+	vel.Neg(&vel)
+	return
+}
+
+// Frame returns the frame of SolarJ2000Ephemeris states, geocentric
+// equatorial J2000.
+func (SolarJ2000Ephemeris) Frame() Frame {
+	return Frame{Origin: OriginEarth, Equatorial: true}
+}
+
+// SPKEphemeris is an Ephemeris of one body of an SPK.
+//
+// As the Ephemeris interface has no error result, State returns NaN
+// coordinates where the SPK has no data.
+type SPKEphemeris struct {
+	spk            *SPK
+	target, center int
+}
+
+// NewSPKEphemeris constructs an SPKEphemeris for target relative to center.
+//
+// Target and center are NAIF integer codes.  Center must be NAIFSSB,
+// NAIFSun, or NAIFEarth, corresponding to the Origin of the Frame, or an
+// error is returned.
+func NewSPKEphemeris(s *SPK, target, center int) (*SPKEphemeris, error) {
+	switch center {
+	case NAIFSSB, NAIFSun, NAIFEarth:
+	default:
+		return nil, fmt.Errorf("Center %d is not SSB, Sun, or Earth.", center)
+	}
+	return &SPKEphemeris{s, target, center}, nil
+}
+
+// State returns position and velocity of the target relative to the center.
+func (s *SPKEphemeris) State(jde float64) (pos, vel coord.Cart) {
+	pos, vel, err := s.spk.State(s.target, s.center, jde)
+	if err != nil {
+		return nanState()
+	}
+	return
+}
+
+// nanState returns NaN position and velocity, the State of an Ephemeris
+// where it has no result.
+func nanState() (pos, vel coord.Cart) {
+	n := math.NaN()
+	pos = coord.Cart{X: n, Y: n, Z: n}
+	return pos, pos
+}
+
+// Frame returns the frame of SPK states, equatorial J2000, with the
+// origin corresponding to the center.
+func (s *SPKEphemeris) Frame() Frame {
+	f := Frame{Equatorial: true}
+	switch s.center {
+	case NAIFSSB:
+		f.Origin = OriginBarycenter
+	case NAIFEarth:
+		f.Origin = OriginEarth
+	}
+	return f
+}
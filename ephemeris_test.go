@@ -0,0 +1,117 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/unit"
+)
+
+func ExampleEphemeris() {
+	s, err := astro.OpenSPK("testdata/test.bsp")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+	k := &astro.Elements{
+		Axis:  2.2091404,
+		Ecc:   .8502196,
+		Inc:   unit.AngleFromDeg(11.94524),
+		ArgP:  unit.AngleFromDeg(186.23352),
+		Node:  unit.AngleFromDeg(334.75006),
+		TimeP: 2448192.5 + .54502,
+	}
+	se, err := astro.NewSPKEphemeris(s, astro.NAIFEarth, astro.NAIFSun)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, e := range []astro.Ephemeris{
+		astro.Se2000Ephemeris{},
+		astro.NewOrbit(k),
+		se,
+	} {
+		p, _ := e.State(astro.J2000)
+		fmt.Printf("%-29s r = %.6f AU\n", e.Frame(), math.Sqrt(p.Square()))
+	}
+	// Output:
+	// geocentric equatorial of date r = 0.983306 AU
+	// heliocentric equatorial J2000 r = 3.066983 AU
+	// heliocentric equatorial J2000 r = 0.747391 AU
+}
+
+// velocity of an Ephemeris should be the derivative of position.
+func testEphemerisVelocity(t *testing.T, e astro.Ephemeris, jde, tol float64) {
+	const h = 1. / 64
+	p, v := e.State(jde)
+	p1, _ := e.State(jde - h)
+	p2, _ := e.State(jde + h)
+	d := math.Abs(v.X-(p2.X-p1.X)/(2*h)) +
+		math.Abs(v.Y-(p2.Y-p1.Y)/(2*h)) +
+		math.Abs(v.Z-(p2.Z-p1.Z)/(2*h))
+	if d > tol {
+		t.Errorf("%T at %.2f: velocity error %g, p %v", e, jde, d, p)
+	}
+}
+
+func TestEphemeris(t *testing.T) {
+	for _, jde := range []float64{2451545, 2448170.5, 2460000.25} {
+		testEphemerisVelocity(t, astro.Se2000Ephemeris{}, jde, 1e-8)
+	}
+	s, err := astro.OpenSPK("testdata/test.bsp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	e, err := astro.NewSPKEphemeris(s, astro.NAIFMoon, astro.NAIFEarth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	testEphemerisVelocity(t, e, astro.J2000+3, 1e-10)
+	if f := e.Frame(); f != (astro.Frame{Origin: astro.OriginEarth,
+		Equatorial: true}) {
+		t.Error("SPK frame:", f)
+	}
+	// out of range of the SPK
+	if p, _ := e.State(astro.J2000 + 100); !math.IsNaN(p.X) {
+		t.Error("expected NaN, got", p)
+	}
+	if _, err := astro.NewSPKEphemeris(s, astro.NAIFEarth,
+		astro.NAIFMoon); err == nil {
+		t.Error("Moon center: expected error")
+	}
+}
+
+func TestSolarJ2000Ephemeris(t *testing.T) {
+	// synthetic Earth series of VSOP87B: L = 1.75 + 6283.0758τ, R = 1
+	dir := t.TempDir()
+	writeV87(t, dir, astro.V87B, astro.Earth, [][][][3]float64{
+		{{{1.75, 0, 0}}, {{6283.0758, 0, 0}}},
+		{{{.0001, 0, 0}}},
+		{{{1, 0, 0}}},
+	})
+	e, err := astro.LoadPlanetPathVersion(astro.Earth, astro.V87B, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f := e.Frame(); f != (astro.Frame{}) {
+		t.Error("V87B frame:", f)
+	}
+	s := astro.SolarJ2000Ephemeris{Earth: e}
+	jde := 2448908.5
+	p, v := s.State(jde)
+	x, y, z, _ := astro.SolarPositionJ2000(e, jde)
+	if d := math.Abs(p.X-x) + math.Abs(p.Y-y) + math.Abs(p.Z-z); d > 1e-14 {
+		t.Error("position differs from SolarPositionJ2000 by", d)
+	}
+	var pv coord.Cart
+	if d := math.Abs(pv.Cross(&p, &v).Square()); d == 0 {
+		t.Error("zero angular momentum")
+	}
+	testEphemerisVelocity(t, s, jde, 1e-9)
+}
@@ -0,0 +1,69 @@
+// Public domain
+
+package astro
+
+// Geocentric: Chapter 33, Elliptic Motion.
+
+import (
+	"math"
+
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/unit"
+)
+
+// LightTime is the light-time for one AU, in days.
+const LightTime = float64(AU) / C / 86400
+
+// Geocentric holds the geocentric ephemeris of an object at one time.
+//
+// Coordinates are astrometric, that is corrected for light-time but not
+// for aberration or nutation, and referenced to the equator and equinox
+// of J2000.
+type Geocentric struct {
+	RA    unit.RA    // Right ascension, α
+	Dec   unit.Angle // Declination, δ
+	Delta float64    // Distance from Earth, Δ, in AU
+	R     float64    // Distance from Sun, r, in AU
+	Elong unit.Angle // Elongation from the Sun, ψ
+	Phase unit.Angle // Phase angle, β
+	Tau   float64    // Light-time, τ, in days
+	Pos   coord.Cart // Geocentric position in AU, light-time corrected
+}
+
+// Geocentric computes a geocentric ephemeris for the orbit.
+//
+// Argument sun is the geocentric position of the Sun at jde in rectangular
+// equatorial coordinates referenced to J2000, for example as returned by
+// SolarPositionJ2000.
+//
+// The heliocentric position of the object is computed at jde - τ, where τ is
+// the light-time for the geocentric distance Δ.
+func (o *Orbit) Geocentric(jde float64, sun *coord.Cart) (g Geocentric) {
+	var h coord.Cart // heliocentric position
+	for i := 0; i < 10; i++ {
+		h.X, h.Y, h.Z, g.R = o.Position(jde - g.Tau)
+		// (33.10) p. 229
+		g.Pos.Add(&h, sun)
+		g.Delta = math.Sqrt(g.Pos.Square())
+		τ := LightTime * g.Delta
+		if math.Abs(τ-g.Tau) < 1e-9 {
+			break
+		}
+		g.Tau = τ
+	}
+	// (33.1) p. 223
+	g.RA = unit.RAFromRad(math.Atan2(g.Pos.Y, g.Pos.X))
+	g.Dec = unit.Angle(math.Asin(g.Pos.Z / g.Delta))
+	g.Elong = vectorAngle(&g.Pos, sun)
+	var sunObj, earthObj coord.Cart
+	sunObj.Neg(&h)
+	earthObj.Neg(&g.Pos)
+	g.Phase = vectorAngle(&sunObj, &earthObj)
+	return
+}
+
+// vectorAngle returns the angle between vectors a and b.
+func vectorAngle(a, b *coord.Cart) unit.Angle {
+	var c coord.Cart
+	return unit.Angle(math.Atan2(math.Sqrt(c.Cross(a, b).Square()), a.Dot(b)))
+}
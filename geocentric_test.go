@@ -0,0 +1,94 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/astro/vsop87b"
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/sexagesimal"
+	"github.com/soniakeys/unit"
+)
+
+// loadPlanet returns VSOP87B series for ibody from the data embedded in
+// package vsop87b, or else from the VSOP87 files.  The test is skipped if
+// neither is available.
+func loadPlanet(t *testing.T, ibody int) *astro.V87Planet {
+	if vsop87b.Available(ibody) {
+		p, err := vsop87b.LoadPlanet(ibody)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	if os.Getenv("VSOP87") == "" {
+		t.Skip("no VSOP87B data")
+	}
+	p, err := astro.LoadPlanet(ibody)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestOrbitGeocentricMeeus(t *testing.T) {
+	// Example 33.b, p. 232.
+	e := loadPlanet(t, astro.Earth)
+	o := astro.NewOrbit(&astro.Elements{
+		Axis:  2.2091404,
+		Ecc:   .8502196,
+		Inc:   unit.AngleFromDeg(11.94524),
+		ArgP:  unit.AngleFromDeg(186.23352),
+		Node:  unit.AngleFromDeg(334.75006),
+		TimeP: astro.FFCalendarGregorianToJD(1990, 10, 28.54502),
+	})
+	jde := astro.FFCalendarGregorianToJD(1990, 10, 6)
+	var sun coord.Cart
+	sun.X, sun.Y, sun.Z, _ = astro.SolarPositionJ2000(e, jde)
+	g := o.Geocentric(jde, &sun)
+	if α := fmt.Sprint(sexa.FmtTime(g.RA.Time())); α != "10ʰ34ᵐ14ˢ" {
+		t.Error("α:", α)
+	}
+	if δ := fmt.Sprintf("%.3f", g.Dec.Deg()); δ != "19.159" {
+		t.Error("δ:", δ)
+	}
+	if ψ := fmt.Sprintf("%.2f", g.Elong.Deg()); ψ != "40.51" {
+		t.Error("ψ:", ψ)
+	}
+}
+
+func TestGeocentric(t *testing.T) {
+	o := astro.NewOrbit(&astro.Elements{
+		Axis:  2.2091404,
+		Ecc:   .8502196,
+		Inc:   unit.AngleFromDeg(11.94524),
+		ArgP:  unit.AngleFromDeg(186.23352),
+		Node:  unit.AngleFromDeg(334.75006),
+		TimeP: 2448193.04502,
+	})
+	jde := 2448170.5
+	sun, _, _ := astro.Se2000(jde - astro.JMod)
+	g := o.Geocentric(jde, &sun)
+	x, y, z, r := o.Position(jde - g.Tau)
+	if d := math.Abs(g.Tau - astro.LightTime*g.Delta); d > 1e-9 {
+		t.Errorf("light-time inconsistent by %g day", d)
+	}
+	if math.Abs(g.R-r) > 1e-12 ||
+		math.Abs(g.Pos.X-x-sun.X)+math.Abs(g.Pos.Y-y-sun.Y)+
+			math.Abs(g.Pos.Z-z-sun.Z) > 1e-12 {
+		t.Error("position inconsistent")
+	}
+	R := math.Sqrt(sun.Square())
+	Δ := g.Delta
+	cψ := (R*R + Δ*Δ - r*r) / (2 * R * Δ)
+	cβ := (r*r + Δ*Δ - R*R) / (2 * r * Δ)
+	if math.Abs(g.Elong.Cos()-cψ) > 1e-9 ||
+		math.Abs(g.Phase.Cos()-cβ) > 1e-9 {
+		t.Errorf("ψ %f β %f", g.Elong.Deg(), g.Phase.Deg())
+	}
+}
@@ -0,0 +1,121 @@
+// Public domain
+
+package astro
+
+// JD: two-part Julian dates.
+
+import (
+	"math"
+	"time"
+
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/unit"
+)
+
+// JD is a Julian date in two parts, Day + Frac.
+//
+// A float64 Julian date near the present resolves only about 40 µs.  Held
+// as an integral day and a fraction, the resolution is better than a
+// nanosecond.  Values returned by functions and methods here are
+// normalized so that Day is integral and 0 <= Frac < 1.  As with float64
+// Julian dates, the day begins at noon.
+type JD struct {
+	Day, Frac float64
+}
+
+// NewJD returns the normalized JD for the sum day + frac.
+func NewJD(day, frac float64) JD {
+	d := math.Floor(day)
+	f := day - d + frac
+	fd := math.Floor(f)
+	return JD{d + fd, f - fd}
+}
+
+// TimeToJD2 returns the JD of a Go time.Time, preserving nanoseconds.
+//
+// As with TimeToJD, the result is a UTC Julian date.
+func TimeToJD2(t time.Time) JD {
+	u := t.UTC()
+	y, m, d := u.Date()
+	h, mi, s := u.Clock()
+	ns := (int64(h)*3600+int64(mi)*60+int64(s))*1e9 + int64(u.Nanosecond())
+	// midnight is JD x.5, so noon-based Day is one less
+	day := FFCalendarGregorianToJD(y, int(m), float64(d)) - .5
+	return NewJD(day, .5+float64(ns)/float64(24*time.Hour))
+}
+
+// Float returns the JD as a single float64.
+func (j JD) Float() float64 {
+	return j.Day + j.Frac
+}
+
+// Add returns the JD advanced by days.
+func (j JD) Add(days float64) JD {
+	d := math.Floor(days)
+	return NewJD(j.Day+d, j.Frac+(days-d))
+}
+
+// AddDuration returns the JD advanced by the duration d, with nanosecond
+// resolution.
+func (j JD) AddDuration(d time.Duration) JD {
+	const day = 24 * time.Hour
+	return NewJD(j.Day+float64(d/day), j.Frac+float64(d%day)/float64(day))
+}
+
+// Sub returns the difference j − k in days.
+func (j JD) Sub(k JD) float64 {
+	return (j.Day - k.Day) + (j.Frac - k.Frac)
+}
+
+// J2000Century returns the number of Julian centuries since J2000.
+func (j JD) J2000Century() float64 {
+	return ((j.Day - J2000) + j.Frac) / JulianCentury
+}
+
+// Time returns the JD as a Go time.Time in UTC, rounded to the nearest
+// nanosecond.
+func (j JD) Time() time.Time {
+	// MJD 0, 1858 November 17, begins at JD 2400000.5.  time.Date
+	// normalizes the day and nanoseconds.
+	n := NewJD(j.Day, j.Frac+.5)
+	ns := math.Round(n.Frac * float64(24*time.Hour))
+	return time.Date(1858, 11, 17+int(n.Day-2400001), 0, 0, 0, int(ns),
+		time.UTC)
+}
+
+// Calendar returns the calendar date of the JD as JDToCalendar.
+func (j JD) Calendar() (year, month int, day float64) {
+	n := NewJD(j.Day, j.Frac+.5)
+	z := int64(n.Day)
+	return meeusJDToCalendar(z, n.Frac, z >= 2299161)
+}
+
+// Position2000JD returns the position of the body as Position2000, at
+// the two-part date jde.
+func (vt *V87Planet) Position2000JD(jde JD) (L, B unit.Angle, R float64) {
+	return vt.spherical(jde.J2000Century() * .1)
+}
+
+// StateJD returns position and velocity of the body as State, at the
+// two-part date jde.
+func (vt *V87Planet) StateJD(jde JD) (p, v coord.Cart) {
+	return vt.state(jde.J2000Century() * .1)
+}
+
+// PositionJD returns coordinates for the orbit as Position, at the
+// two-part date jde.
+func (o *Orbit) PositionJD(jde JD) (x, y, z, r float64) {
+	return o.position((jde.Day - o.k.TimeP) + jde.Frac)
+}
+
+// PositionVelocityJD returns position and velocity for the orbit as
+// PositionVelocity, at the two-part date jde.
+func (o *Orbit) PositionVelocityJD(jde JD) (p, v coord.Cart) {
+	return o.positionVelocity((jde.Day - o.k.TimeP) + jde.Frac)
+}
+
+// StateJD returns position and velocity as State, at the two-part date
+// jde.
+func (s *SPK) StateJD(target, center int, jde JD) (p, v coord.Cart, err error) {
+	return s.state(target, center, ((jde.Day-J2000)+jde.Frac)*spkDay)
+}
@@ -0,0 +1,126 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/unit"
+)
+
+func ExampleTimeToJD2() {
+	t := time.Date(2024, 3, 1, 12, 0, 0, 123456789, time.UTC)
+	j := astro.TimeToJD2(t)
+	fmt.Printf("%.0f + %.15f\n", j.Day, j.Frac)
+	fmt.Println(j.Time())
+	fmt.Println(astro.JDToTime(j.Float()))
+	// Output:
+	// 2460371 + 0.000001428898021
+	// 2024-03-01 12:00:00.123456789 +0000 UTC
+	// 2024-03-01 12:00:00.123475492 +0000 UTC
+}
+
+func TestJD(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1e4; i++ {
+		tm := time.Unix(r.Int63n(1e10)-5e9, r.Int63n(1e9)).UTC()
+		j := astro.TimeToJD2(tm)
+		if j.Day != math.Floor(j.Day) || j.Frac < 0 || j.Frac >= 1 {
+			t.Fatalf("%v: not normalized: %v", tm, j)
+		}
+		if r := j.Time(); !r.Equal(tm) {
+			t.Fatalf("%v: round trip %v", tm, r)
+		}
+		if d := math.Abs(j.Float() - astro.TimeToJD(tm)); d > 1e-9 {
+			t.Fatalf("%v: differs from TimeToJD by %g", tm, d)
+		}
+		d := time.Duration(r.Int63n(1e17) - 5e16)
+		k := j.AddDuration(d)
+		if r := k.Time(); !r.Equal(tm.Add(d)) {
+			t.Fatalf("%v + %v: got %v", tm, d, r)
+		}
+		if s := k.Sub(j); math.Abs(s-float64(d)/float64(24*time.Hour)) > 1e-12 {
+			t.Fatalf("Sub: %g days, want %v", s, d)
+		}
+		y, m, dd := j.Calendar()
+		y2, m2, d2 := astro.JDToCalendar(j.Float())
+		if y != y2 || m != m2 || math.Abs(dd-d2) > 1e-9 {
+			t.Fatalf("%v: Calendar %d-%d-%f, JDToCalendar %d-%d-%f",
+				tm, y, m, dd, y2, m2, d2)
+		}
+	}
+	j := astro.NewJD(2451545.75, -1.5)
+	if j != (astro.JD{Day: 2451544, Frac: .25}) {
+		t.Error("NewJD:", j)
+	}
+	if j = j.Add(-.5); j != (astro.JD{Day: 2451543, Frac: .75}) {
+		t.Error("Add:", j)
+	}
+}
+
+func TestPositionJD(t *testing.T) {
+	k := &astro.Elements{
+		Axis:  2.2091404,
+		Ecc:   .8502196,
+		Inc:   unit.AngleFromDeg(11.94524),
+		ArgP:  unit.AngleFromDeg(186.23352),
+		Node:  unit.AngleFromDeg(334.75006),
+		TimeP: 2448192.5 + .54502,
+	}
+	o := astro.NewOrbit(k)
+	j := astro.JD{Day: 2448170, Frac: .5}
+	x, y, z, r := o.Position(j.Float())
+	x2, y2, z2, r2 := o.PositionJD(j)
+	if x != x2 || y != y2 || z != z2 || r != r2 {
+		t.Error("Orbit.PositionJD differs from Position")
+	}
+	p, v := o.PositionVelocity(j.Float())
+	p2, v2 := o.PositionVelocityJD(j)
+	if p != p2 || v != v2 {
+		t.Error("Orbit.PositionVelocityJD differs from PositionVelocity")
+	}
+
+	dir := t.TempDir()
+	writeV87(t, dir, astro.V87B, astro.Earth, [][][][3]float64{
+		{{{1.75, 0, 0}}, {{6283.0758, 0, 0}}},
+		{{{.0001, 0, 0}}},
+		{{{1, 0, 0}}},
+	})
+	e, err := astro.LoadPlanetPathVersion(astro.Earth, astro.V87B, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	L, B, R := e.Position2000(j.Float())
+	L2, B2, R2 := e.Position2000JD(j)
+	if L != L2 || B != B2 || R != R2 {
+		t.Error("V87Planet.Position2000JD differs from Position2000")
+	}
+	p, v = e.State(j.Float())
+	p2, v2 = e.StateJD(j)
+	if p != p2 || v != v2 {
+		t.Error("V87Planet.StateJD differs from State")
+	}
+
+	s, err := astro.OpenSPK("testdata/test.bsp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	j = astro.JD{Day: astro.J2000, Frac: .25}
+	p, v, err = s.State(astro.NAIFMoon, astro.NAIFEarth, j.Float())
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, v2, err = s.StateJD(astro.NAIFMoon, astro.NAIFEarth, j)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p != p2 || v != v2 {
+		t.Error("SPK.StateJD differs from State")
+	}
+}
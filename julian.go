@@ -2,7 +2,10 @@
 
 package astro
 
-import "time"
+import (
+	"math"
+	"time"
+)
 
 // MeeusCalendarGregorianToJD converts a Gregorian year, month, and day of
 // month to Julian day.
@@ -26,6 +29,23 @@ func MeeusCalendarGregorianToJD(y, m int, d float64) float64 {
 		float64(floorDiv(306*(m+1), 10)+b) + d - 1524.5
 }
 
+// CalendarJulianToJD converts a Julian calendar year, month, and day of
+// month to Julian day.
+//
+// Years are astronomical, so the year before 1 is 0.  Negative years are
+// valid.  The Julian calendar is used for all dates; see CalendarToJD for
+// a conversion that switches to the Gregorian calendar.
+func CalendarJulianToJD(y, m int, d float64) float64 {
+	switch m {
+	case 1, 2:
+		y--
+		m += 12
+	}
+	// (7.1) p. 61, with B = 0
+	return float64(floorDiv64(36525*(int64(y+4716)), 100)) +
+		float64(floorDiv(306*(m+1), 10)) + d - 1524.5
+}
+
 // FFCalendarGregorianToJD converts a Gregorian year, month, and day of
 // month to Julian day.
 //
@@ -76,7 +96,8 @@ const (
 // TimeToJD takes a Go time.Time and returns a JD as float64.
 //
 // Any time zone offset in the time.Time is ignored and the time is
-// treated as UTC.
+// treated as UTC.  The result is thus a UTC Julian date and not a jde.
+// Use EpochFromTime and Epoch.TT for a jde.
 func TimeToJD(t time.Time) float64 {
 	ut := t.UTC()
 	y, m, _ := ut.Date()
@@ -84,3 +105,217 @@ func TimeToJD(t time.Time) float64 {
 	// time.Time is always Gregorian
 	return FFCalendarGregorianToJD(y, int(m), float64(d)/float64(24*time.Hour))
 }
+
+// JDToCalendar returns the calendar date for the given Julian day.
+//
+// Dates on or after 1582 October 15 are in the Gregorian calendar, earlier
+// dates are in the Julian calendar.  Years are astronomical, so the year
+// before 1 is 0, and the one before that is -1.  Negative Julian days are
+// valid.
+//
+// The algorithm is that of Meeus p. 63, with floor truncation.
+func JDToCalendar(jd float64) (year, month int, day float64) {
+	z, f := splitJD(jd)
+	return meeusJDToCalendar(z, f, z >= 2299161)
+}
+
+// splitJD returns the Julian day number z and the fraction f of the day
+// since the preceding midnight, such that z + f = jd + .5 and 0 <= f < 1.
+func splitJD(jd float64) (z int64, f float64) {
+	zf, f := math.Modf(jd + .5)
+	if f < 0 {
+		zf--
+		f++
+	}
+	return int64(zf), f
+}
+
+// meeusJDToCalendar returns the date for Julian day number z and fraction
+// f in the Gregorian calendar if gregorian is true, otherwise in the Julian
+// calendar.
+func meeusJDToCalendar(z int64, f float64, gregorian bool) (year, month int, day float64) {
+	// shift negative days by whole calendar cycles
+	var cy, cd, ny int64 = 0, 1461, 4
+	if gregorian {
+		cd, ny = 146097, 400
+	}
+	if z < 0 {
+		cy = (-z)/cd + 1
+		z += cy * cd
+	}
+	a := z
+	if gregorian {
+		α := floorDiv64(z*100-186721625, 3652425)
+		a = z + 1 + α - floorDiv64(α, 4)
+	}
+	b := a + 1524
+	c := floorDiv64(b*100-12210, 36525)
+	d := floorDiv64(36525*c, 100)
+	e := floorDiv64((b-d)*10000, 306001)
+	// (7.2) p. 63
+	day = float64(b-d-floorDiv64(306001*e, 10000)) + f
+	if e < 14 {
+		month = int(e - 1)
+	} else {
+		month = int(e - 13)
+	}
+	if month > 2 {
+		year = int(c - 4716 - ny*cy)
+	} else {
+		year = int(c - 4715 - ny*cy)
+	}
+	return
+}
+
+// MJDToCalendar returns the Gregorian calendar date for the given Modified
+// Julian Day, the inverse of CalendarGregorianToMJD.
+//
+// Unlike JDToCalendar, the Gregorian calendar is used for all dates.
+func MJDToCalendar(mjd float64) (year, month int, day float64) {
+	df := math.Floor(mjd)
+	// days since March 1 of year 0
+	g := int64(df) + 678881
+	y := floorDiv64(10000*g+14780, 3652425)
+	ddd := g - (365*y + floorDiv64(y, 4) - floorDiv64(y, 100) + floorDiv64(y, 400))
+	if ddd < 0 {
+		y--
+		ddd = g - (365*y + floorDiv64(y, 4) - floorDiv64(y, 100) + floorDiv64(y, 400))
+	}
+	mi := (100*ddd + 52) / 3060
+	month = int((mi+2)%12 + 1)
+	year = int(y + (mi+2)/12)
+	day = float64(ddd-(mi*306+5)/10+1) + mjd - df
+	return
+}
+
+// JDToTime returns a Go time.Time in UTC for a Julian day.
+//
+// The time.Time is always Gregorian.  The result is rounded to the
+// nearest nanosecond, although the resolution of a float64 Julian day near
+// the present is only tens of microseconds.
+func JDToTime(jd float64) time.Time {
+	mjd := jd - JMod
+	df := math.Floor(mjd)
+	ns := math.Round((mjd - df) * float64(24*time.Hour))
+	// MJD 0 is 1858 November 17.  time.Date normalizes the day and
+	// nanoseconds.
+	return time.Date(1858, 11, 17+int(df), 0, 0, 0, int(ns), time.UTC)
+}
+
+// CalendarReform is the date of adoption of the Gregorian calendar, given
+// as the first day of the Gregorian calendar.  Earlier dates are in the
+// Julian calendar.
+type CalendarReform struct {
+	Year, Month, Day int
+}
+
+// Reform1582 is the Gregorian reform of 1582, where Julian calendar date
+// 1582 October 4 was followed by Gregorian calendar date October 15.
+//
+// Other dates of reform may be constructed, for example
+// CalendarReform{1752, 9, 14} for Great Britain and its colonies.
+var Reform1582 = CalendarReform{1582, 10, 15}
+
+// JD returns the Julian day of the start of the first day of the
+// Gregorian calendar.
+func (r CalendarReform) JD() float64 {
+	return MeeusCalendarGregorianToJD(r.Year, r.Month, float64(r.Day))
+}
+
+// gregorian returns true if the calendar date is on or after r.
+func (r CalendarReform) gregorian(y, m int, d float64) bool {
+	switch {
+	case y != r.Year:
+		return y > r.Year
+	case m != r.Month:
+		return m > r.Month
+	}
+	return d >= float64(r.Day)
+}
+
+// ToJD converts a calendar date to Julian day.
+//
+// Dates on or after the reform are taken to be in the Gregorian calendar,
+// earlier dates in the Julian calendar.  Dates omitted by the reform, such
+// as 1582 October 10 for Reform1582, are taken to be Julian calendar dates.
+func (r CalendarReform) ToJD(y, m int, d float64) float64 {
+	if r.gregorian(y, m, d) {
+		return MeeusCalendarGregorianToJD(y, m, d)
+	}
+	return CalendarJulianToJD(y, m, d)
+}
+
+// FromJD converts a Julian day to a calendar date, the inverse of ToJD.
+func (r CalendarReform) FromJD(jd float64) (year, month int, day float64) {
+	z, f := splitJD(jd)
+	return meeusJDToCalendar(z, f, float64(z)-.5 >= r.JD())
+}
+
+// CalendarToJD converts a calendar date to Julian day, using the Julian
+// calendar before the Gregorian reform of 1582 and the Gregorian calendar
+// after.
+//
+// It is Reform1582.ToJD, the inverse of JDToCalendar.
+func CalendarToJD(y, m int, d float64) float64 {
+	return Reform1582.ToJD(y, m, d)
+}
+
+// LeapYearJulian returns true if year y is a leap year in the Julian
+// calendar.
+//
+// Years are astronomical, so year 0 and year -4 are leap years.
+func LeapYearJulian(y int) bool {
+	return y&3 == 0
+}
+
+// LeapYearGregorian returns true if year y is a leap year in the Gregorian
+// calendar.
+func LeapYearGregorian(y int) bool {
+	return y&3 == 0 && (y%100 != 0 || y%400 == 0)
+}
+
+// LeapYear returns true if year y is a leap year in the calendar in use
+// in February of the year.
+func (r CalendarReform) LeapYear(y int) bool {
+	if r.gregorian(y, 2, 29) {
+		return LeapYearGregorian(y)
+	}
+	return LeapYearJulian(y)
+}
+
+// DayOfYear returns the day number within the year, 1 for January 1, for
+// a Julian or Gregorian calendar date in a year without a calendar reform.
+//
+// Argument leap is whether the year is a leap year.
+func DayOfYear(m, d int, leap bool) int {
+	k := 2
+	if leap {
+		k = 1
+	}
+	// p. 65
+	return 275*m/9 - k*((m+9)/12) + d - 30
+}
+
+// DayOfYearToCalendar returns the month and day of month for day number n
+// within the year, the inverse of DayOfYear.
+func DayOfYearToCalendar(n int, leap bool) (m, d int) {
+	k := 2
+	if leap {
+		k = 1
+	}
+	// p. 66
+	m = 1
+	if n >= 32 {
+		m = int(float64(9*(k+n))/275 + .98)
+	}
+	d = n - 275*m/9 + k*((m+9)/12) + 30
+	return
+}
+
+// DayOfYear returns the day number within the year, 1 for January 1.
+//
+// Unlike the function DayOfYear, days omitted by the reform are not
+// counted, so for Reform1582, 1582 October 15 is day 278.
+func (r CalendarReform) DayOfYear(y, m, d int) int {
+	return int(r.ToJD(y, m, float64(d))-r.ToJD(y, 1, 1)) + 1
+}
@@ -39,7 +39,7 @@ func ExampleFFCalendarGregorianToJD() {
 	// 27689
 }
 
-func ExampleTimeToJd() {
+func ExampleTimeToJD() {
 	// Meeus example 7.a, p. 61.
 	t := time.Date(1957, 10, 4, 0, 0, 0, 0, time.UTC)
 	ns := 0.81 * float64(24*time.Hour)
@@ -49,13 +49,217 @@ func ExampleTimeToJd() {
 	// 2436116.31
 }
 
+func ExampleJDToCalendar() {
+	// Meeus example 7.c, p. 64.
+	y, m, d := astro.JDToCalendar(2436116.31)
+	fmt.Printf("%d %d %.2f\n", y, m, d)
+	// Meeus exercises, p. 64.
+	y, m, d = astro.JDToCalendar(1842713)
+	fmt.Printf("%d %d %.2f\n", y, m, d)
+	y, m, d = astro.JDToCalendar(1507900.13)
+	fmt.Printf("%d %d %.2f\n", y, m, d)
+	// Output:
+	// 1957 10 4.81
+	// 333 1 27.50
+	// -584 5 28.63
+}
+
+func ExampleJDToTime() {
+	fmt.Println(astro.JDToTime(2436116.31).Round(time.Second))
+	// Output:
+	// 1957-10-04 19:26:24 +0000 UTC
+}
+
+func TestJDToCalendar(t *testing.T) {
+	// switchover, JD 0, and negative JD
+	for _, tc := range []struct {
+		jd      float64
+		y, m, d int
+	}{
+		{2299160.5, 1582, 10, 15},
+		{2299159.5, 1582, 10, 4},
+		{-.5, -4712, 1, 1},
+		{-1461.5, -4716, 1, 1},
+		{-1.5, -4713, 12, 31},
+	} {
+		y, m, d := astro.JDToCalendar(tc.jd)
+		if y != tc.y || m != tc.m || d != float64(tc.d) {
+			t.Errorf("JD %.1f: got %d-%02d-%g, want %d-%02d-%02d",
+				tc.jd, y, m, d, tc.y, tc.m, tc.d)
+		}
+	}
+	// round trip
+	r := rand.New(rand.NewSource(1))
+	j0 := astro.FFCalendarGregorianToJD(1582, 10, 15)
+	for i := 0; i < 1e5; i++ {
+		j := j0 + 3e6*r.Float64()
+		y, m, d := astro.JDToCalendar(j)
+		if jf := astro.FFCalendarGregorianToJD(y, m, d); math.Abs(jf-j) > 1e-6 {
+			t.Fatalf("JD %f: %d-%02d-%f gives %f", j, y, m, d, jf)
+		}
+	}
+}
+
+func TestMJDToCalendar(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1e5; i++ {
+		mjd := -678000 + 2e6*r.Float64()
+		y, m, d := astro.MJDToCalendar(mjd)
+		if m < 1 || m > 12 || d < 1 || d >= 32 {
+			t.Fatalf("MJD %f: %d-%02d-%f", mjd, y, m, d)
+		}
+		if jm := astro.CalendarGregorianToMJD(y, m, d); math.Abs(jm-mjd) > 1e-6 {
+			t.Fatalf("MJD %f: %d-%02d-%f gives %f", mjd, y, m, d, jm)
+		}
+	}
+}
+
+func TestJDToTime(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1e4; i++ {
+		tm := time.Unix(r.Int63n(1e10)-5e9, r.Int63n(1e9)).UTC()
+		if d := math.Abs(astro.TimeToJD(astro.JDToTime(astro.TimeToJD(tm))) -
+			astro.TimeToJD(tm)); d != 0 {
+			t.Fatalf("%v: JD differs by %g", tm, d)
+		}
+		if d := astro.JDToTime(astro.TimeToJD(tm)).Sub(tm); d > 50*time.Microsecond ||
+			d < -50*time.Microsecond {
+			t.Fatalf("%v: time differs by %v", tm, d)
+		}
+	}
+}
+
+func ExampleCalendarJulianToJD() {
+	// Meeus example 7.b, p. 61.
+	fmt.Printf("%.1f\n", astro.CalendarJulianToJD(333, 1, 27.5))
+	// Output:
+	// 1842713.0
+}
+
+func ExampleCalendarReform() {
+	// The British reform of 1752.
+	r := astro.CalendarReform{Year: 1752, Month: 9, Day: 14}
+	jd := r.ToJD(1752, 9, 2)
+	y, m, d := r.FromJD(jd + 1)
+	fmt.Println(y, m, d)
+	fmt.Println(r.DayOfYear(1752, 12, 31))
+	// Output:
+	// 1752 9 14
+	// 355
+}
+
+func TestCalendarToJD(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	// Table p. 62.
+	for _, tc := range []struct {
+		y, m int
+		d    float64
+		jd   float64
+	}{
+		{2000, 1, 1.5, 2451545},
+		{1999, 1, 1, 2451179.5},
+		{1987, 1, 27, 2446822.5},
+		{1987, 6, 19.5, 2446966},
+		{1988, 1, 27, 2447187.5},
+		{1988, 6, 19.5, 2447332},
+		{1900, 1, 1, 2415020.5},
+		{1600, 1, 1, 2305447.5},
+		{1600, 12, 31, 2305812.5},
+		{837, 4, 10.3, 2026871.8},
+		{-123, 12, 31, 1676496.5},
+		{-122, 1, 1, 1676497.5},
+		{-1000, 7, 12.5, 1356001},
+		{-1000, 2, 29, 1355866.5},
+		{-1001, 8, 17.9, 1355671.4},
+		{-4712, 1, 1.5, 0},
+	} {
+		if jd := astro.CalendarToJD(tc.y, tc.m, tc.d); math.Abs(jd-tc.jd) > 1e-6 {
+			t.Errorf("%d-%02d-%g: got %.1f, want %.1f", tc.y, tc.m, tc.d, jd, tc.jd)
+		}
+		y, m, d := astro.Reform1582.FromJD(tc.jd)
+		if y != tc.y || m != tc.m || math.Abs(d-tc.d) > 1e-6 {
+			t.Errorf("%.1f: got %d-%02d-%g", tc.jd, y, m, d)
+		}
+	}
+	// round trip, and each calendar alone
+	for i := 0; i < 1e5; i++ {
+		jd := -2e6 + 5e6*r.Float64()
+		y, m, d := astro.Reform1582.FromJD(jd)
+		if j := astro.CalendarToJD(y, m, d); math.Abs(j-jd) > 1e-6 {
+			t.Fatalf("JD %f: %d-%02d-%f gives %f", jd, y, m, d, j)
+		}
+		y, m, d = astro.CalendarReform{Year: 1e6}.FromJD(jd)
+		if j := astro.CalendarJulianToJD(y, m, d); math.Abs(j-jd) > 1e-6 {
+			t.Fatalf("JD %f: Julian %d-%02d-%f gives %f", jd, y, m, d, j)
+		}
+		y, m, d = astro.CalendarReform{Year: -1e6}.FromJD(jd)
+		y2, m2, d2 := astro.MJDToCalendar(jd - astro.JMod)
+		if y != y2 || m != m2 || math.Abs(d-d2) > 1e-6 {
+			t.Fatalf("JD %f: Gregorian %d-%02d-%f, MJDToCalendar %d-%02d-%f",
+				jd, y, m, d, y2, m2, d2)
+		}
+	}
+}
+
+func TestLeapYear(t *testing.T) {
+	// p. 62
+	for _, y := range []int{900, 1236, 0, -4} {
+		if !astro.LeapYearJulian(y) {
+			t.Error(y, "should be leap")
+		}
+	}
+	for _, y := range []int{750, 1429, -1} {
+		if astro.LeapYearJulian(y) {
+			t.Error(y, "should not be leap")
+		}
+	}
+	for _, y := range []int{1700, 1800, 1900, 2100} {
+		if astro.LeapYearGregorian(y) || !astro.LeapYearJulian(y) {
+			t.Error(y)
+		}
+	}
+	for _, y := range []int{1600, 2000, 2400} {
+		if !astro.LeapYearGregorian(y) {
+			t.Error(y, "should be leap")
+		}
+	}
+	if !astro.Reform1582.LeapYear(1500) || astro.Reform1582.LeapYear(1700) {
+		t.Error("Reform1582.LeapYear")
+	}
+}
+
+func TestDayOfYear(t *testing.T) {
+	// Example 7.f, p. 65.
+	if n := astro.DayOfYear(11, 14, false); n != 318 {
+		t.Error("1978 Nov 14:", n)
+	}
+	if n := astro.DayOfYear(4, 22, true); n != 113 {
+		t.Error("1988 Apr 22:", n)
+	}
+	for _, leap := range []bool{false, true} {
+		days := 365
+		if leap {
+			days = 366
+		}
+		for n := 1; n <= days; n++ {
+			m, d := astro.DayOfYearToCalendar(n, leap)
+			if astro.DayOfYear(m, d, leap) != n {
+				t.Fatalf("day %d leap %t: %d-%d", n, leap, m, d)
+			}
+		}
+	}
+	if n := astro.Reform1582.DayOfYear(1582, 10, 15); n != 278 {
+		t.Error("1582 Oct 15:", n)
+	}
+}
+
 func TestFF(t *testing.T) {
-	rand.Seed(time.Now().UnixNano())
+	r := rand.New(rand.NewSource(1))
 	j0 := julian.CalendarGregorianToJD(1582, 11, 1)
 	j1 := julian.CalendarGregorianToJD(10000, 1, 1)
 	dj := j1 - j0
 	for i := 0; i < 1e5; i++ {
-		j := j0 + dj*rand.Float64()
+		j := j0 + dj*r.Float64()
 		y, m, d := julian.JDToCalendar(j)
 		{
 			ja := astro.MeeusCalendarGregorianToJD(y, m, d)
@@ -82,7 +286,7 @@ func TestFF(t *testing.T) {
 }
 
 func BenchmarkFF(b *testing.B) {
-	rand.Seed(time.Now().UnixNano())
+	r := rand.New(rand.NewSource(1))
 	j0 := julian.CalendarGregorianToJD(1582, 11, 1)
 	j1 := julian.CalendarGregorianToJD(2582, 11, 1)
 	dj := j1 - j0
@@ -92,7 +296,7 @@ func BenchmarkFF(b *testing.B) {
 	}
 	cs := make([]ymd, 1e6)
 	for i := range cs {
-		y, m, d := julian.JDToCalendar(j0 + dj*rand.Float64())
+		y, m, d := julian.JDToCalendar(j0 + dj*r.Float64())
 		cs[i] = ymd{y, m, d}
 	}
 	b.Run("Meeus", func(b *testing.B) {
@@ -0,0 +1,211 @@
+// Public domain
+
+package astro
+
+// Kepler: Chapter 30, Equation of Kepler.
+
+import (
+	"errors"
+	"math"
+
+	"github.com/soniakeys/unit"
+)
+
+// ErrMaxIterations is returned by iterative solvers that fail to converge.
+var ErrMaxIterations = errors.New("Maximum iterations reached")
+
+// KeplerSolver is the common signature of the Kepler equation solvers
+// KeplerNewton, KeplerDanby, KeplerMarkley, and KeplerBinary.
+//
+// Argument e is eccentricity, 0 <= e < 1.  M is mean anomaly.
+//
+// Result E is eccentric anomaly.  M is first reduced to the range -π to π
+// and E is returned in the same range.  Solvers in this package return E
+// satisfying E - e sin E = M to within KeplerTolerance radian.
+//
+// Result iter is the number of iterations or corrections used.  A non-nil
+// err indicates failure to converge; E is then the last iterate and does
+// not meet the accuracy contract.
+type KeplerSolver func(e float64, M unit.Angle) (E unit.Angle, iter int, err error)
+
+// KeplerTolerance is the accuracy, in radians, of solutions returned by the
+// Kepler equation solvers in this package, as measured by the residual
+// of Kepler's equation.
+const KeplerTolerance = 1e-14
+
+// reduce returns M reduced to the range -π to π.
+func reduce(M unit.Angle) float64 {
+	m := M.Mod1().Rad()
+	if m > math.Pi {
+		m -= 2 * math.Pi
+	}
+	return m
+}
+
+// KeplerNewton solves Kepler's equation by Newton's method, starting from
+// E = M.
+//
+// The step is limited by the method of Steele, Meeus p. 205, which avoids
+// divergence for large e.  Convergence is typically within a few iterations
+// but can take a dozen or more for e near 1.
+func KeplerNewton(e float64, M unit.Angle) (E unit.Angle, iter int, err error) {
+	m := reduce(M)
+	return newton(e, m, m)
+}
+
+// newton iterates the Steele-limited Newton step from starting value E0.
+func newton(e, m, E0 float64) (unit.Angle, int, error) {
+	dLast := math.Inf(1)
+	for i := 1; i <= 50; i++ {
+		se, ce := math.Sincos(E0)
+		f := m + e*se - E0
+		d := f / (1 - e*ce)
+		if d > .5 {
+			d = .5
+		} else if d < -.5 {
+			d = -.5
+		}
+		E0 += d
+		if converged(d, dLast, f, E0) {
+			return unit.Angle(E0), i, nil
+		}
+		dLast = d
+	}
+	return unit.Angle(E0), 50, ErrMaxIterations
+}
+
+// converged is the stopping test of the iterative solvers, given the step
+// d just taken, the previous step dLast, the residual f before the step,
+// and the new iterate E.
+//
+// The step must be small relative to E.  For e near 1 and small M though,
+// rounding in the residual is magnified by the small derivative and the
+// step may not get that small.  The iteration is then stopped when the step
+// no longer decreases and the residual is within KeplerTolerance.
+func converged(d, dLast, f, E float64) bool {
+	ad := math.Abs(d)
+	return ad <= 1e-15*(1+math.Abs(E)) ||
+		ad >= math.Abs(dLast) && math.Abs(f) <= KeplerTolerance
+}
+
+// KeplerDanby solves Kepler's equation by Halley's method, starting from
+// Danby's initial value E = M + .85e sign(sin M).
+//
+// Convergence is cubic and reliable for all e < 1, typically within three
+// or four iterations.
+func KeplerDanby(e float64, M unit.Angle) (E unit.Angle, iter int, err error) {
+	m := reduce(M)
+	E0 := m + .85*e
+	if m < 0 {
+		E0 = m - .85*e
+	}
+	dLast := math.Inf(1)
+	for i := 1; i <= 50; i++ {
+		se, ce := math.Sincos(E0)
+		f := E0 - e*se - m
+		f1 := 1 - e*ce
+		d := -f / (f1 - .5*f*e*se/f1)
+		E0 += d
+		if converged(d, dLast, f, E0) {
+			return unit.Angle(E0), i, nil
+		}
+		dLast = d
+	}
+	return unit.Angle(E0), 50, ErrMaxIterations
+}
+
+// KeplerMarkley solves Kepler's equation by the non-iterative method of
+// Markley, Celestial Mechanics 63, 101 (1995).
+//
+// A starting value from a cubic approximation is refined by a single
+// fifth order correction.  Result iter is always 1 and err is always nil.
+func KeplerMarkley(e float64, M unit.Angle) (E unit.Angle, iter int, err error) {
+	m := reduce(M)
+	if m == 0 {
+		return 0, 1, nil
+	}
+	// work with |M|, restore sign at the end
+	am := math.Abs(m)
+	const π2 = math.Pi * math.Pi
+	α := (3*π2 + 1.6*math.Pi*(math.Pi-am)/(1+e)) / (π2 - 6)
+	d := 3*(1-e) + α*e
+	q := 2*α*d*(1-e) - am*am
+	r := 3*α*d*(d-1+e)*am + am*am*am
+	w := math.Pow(math.Abs(r)+math.Sqrt(q*q*q+r*r), 2./3)
+	E1 := (2*r*w/(w*w+w*q+q*q) + am) / d
+	// fifth order correction
+	se, ce := math.Sincos(E1)
+	f0 := E1 - e*se - am
+	f1 := 1 - e*ce
+	f2 := e * se
+	f3 := e * ce
+	f4 := -f2
+	δ3 := -f0 / (f1 - .5*f0*f2/f1)
+	δ4 := -f0 / (f1 + .5*δ3*f2 + δ3*δ3*f3/6)
+	δ5 := -f0 / (f1 + .5*δ4*f2 + δ4*δ4*f3/6 + δ4*δ4*δ4*f4/24)
+	E1 += δ5
+	if m < 0 {
+		E1 = -E1
+	}
+	return unit.Angle(E1), 1, nil
+}
+
+// KeplerBinary solves Kepler's equation by binary search.
+//
+// The method is slow but cannot fail.  Result iter is always 53 and err is
+// always nil.
+func KeplerBinary(e float64, M unit.Angle) (E unit.Angle, iter int, err error) {
+	// adapted from BASIC, Meeus p. 206
+	MR := reduce(M)
+	f := 1
+	if MR < 0 {
+		f = -1
+		MR = -MR
+	}
+	E0 := math.Pi * .5
+	d := math.Pi * .25
+	for i := 0; i < 53; i++ {
+		M1 := E0 - e*math.Sin(E0)
+		if MR-M1 < 0 {
+			E0 -= d
+		} else {
+			E0 += d
+		}
+		d *= .5
+	}
+	if f < 0 {
+		E0 = -E0
+	}
+	return unit.Angle(E0), 53, nil
+}
+
+// kepler is the default solver for Orbit.  It uses Newton's method,
+// falling back to binary search in case of non-convergence.
+func kepler(e float64, M unit.Angle) (E unit.Angle, iter int, err error) {
+	if E, iter, err = KeplerNewton(e, M); err == nil {
+		return
+	}
+	E, _, err = KeplerBinary(e, M)
+	return E, iter + 53, err
+}
+
+// keplerHyperbolic solves the hyperbolic form of Kepler's equation,
+// e sinh H - H = M, by Newton's method.
+//
+// Argument e is eccentricity (> 1), M is mean anomaly.  Result H is the
+// hyperbolic anomaly.
+func keplerHyperbolic(e, M float64) float64 {
+	// starting value good for large |M|, adequate for small
+	H := math.Log(2*math.Abs(M)/e + 1.8)
+	if M < 0 {
+		H = -H
+	}
+	for i := 0; i < 50; i++ {
+		d := (e*math.Sinh(H) - H - M) / (e*math.Cosh(H) - 1)
+		H -= d
+		if math.Abs(d) <= 1e-15*(1+math.Abs(H)) {
+			break
+		}
+	}
+	return H
+}
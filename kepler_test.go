@@ -0,0 +1,112 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/unit"
+)
+
+var solvers = []struct {
+	name  string
+	solve astro.KeplerSolver
+}{
+	{"Newton", astro.KeplerNewton},
+	{"Danby", astro.KeplerDanby},
+	{"Markley", astro.KeplerMarkley},
+	{"Binary", astro.KeplerBinary},
+}
+
+func ExampleKeplerNewton() {
+	// Example 30.a, p. 199.
+	E, _, err := astro.KeplerNewton(.1, unit.AngleFromDeg(5))
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%.9f\n", E.Deg())
+	// Output:
+	// 5.554589254
+}
+
+func ExampleKeplerMarkley() {
+	// Example 30.b, p. 199.
+	E, _, _ := astro.KeplerMarkley(.99, .2)
+	fmt.Printf("%.12f\n", E)
+	// Output:
+	// 1.066997365282
+}
+
+func TestKeplerMeeus(t *testing.T) {
+	for _, s := range solvers {
+		// Example 30.a, p. 199.
+		E, _, err := s.solve(.1, unit.AngleFromDeg(5))
+		if err != nil || math.Abs(E.Deg()-5.554589254) > 1e-9 {
+			t.Errorf("%s 30.a: E = %.10f°, err %v", s.name, E.Deg(), err)
+		}
+		// Example 30.b, p. 199.
+		E, _, err = s.solve(.99, .2)
+		if err != nil || math.Abs(E.Rad()-1.066997365282) > 1e-12 {
+			t.Errorf("%s 30.b: E = %.12f, err %v", s.name, E, err)
+		}
+	}
+}
+
+func TestKeplerAccuracy(t *testing.T) {
+	for _, s := range solvers {
+		maxIter := 0
+		check := func(e float64, M unit.Angle) {
+			E, iter, err := s.solve(e, M)
+			if err != nil {
+				t.Fatalf("%s e %g M %g°: %v", s.name, e, M.Deg(), err)
+			}
+			if E < -math.Pi || E > math.Pi {
+				t.Fatalf("%s e %g M %g°: E = %g out of range",
+					s.name, e, M.Deg(), E)
+			}
+			r := math.Remainder(E.Rad()-e*E.Sin()-M.Rad(), 2*math.Pi)
+			if math.Abs(r) > astro.KeplerTolerance {
+				t.Fatalf("%s e %g M %g°: residual %g",
+					s.name, e, M.Deg(), r)
+			}
+			if iter > maxIter {
+				maxIter = iter
+			}
+		}
+		for _, e := range []float64{0, .01, .1, .5, .9, .99, .999, .9999,
+			.99999, .999999} {
+			for d := -400.; d <= 400; d += .25 {
+				check(e, unit.AngleFromDeg(d))
+			}
+			// small M, where rounding limits convergence for e near 1
+			for x := -12.; x <= 0; x += .01 {
+				M := unit.Angle(math.Pow(10, x))
+				check(e, M)
+				check(e, -M)
+			}
+		}
+		// cases that once failed to converge
+		check(.99835, 6.5e-5)
+		check(.99402, -.00421)
+		for _, e := range []float64{.99, .999} {
+			for d := -5.; d <= 5; d += .01 {
+				check(e, unit.AngleFromDeg(d))
+			}
+		}
+		t.Logf("%s: max iterations %d", s.name, maxIter)
+	}
+}
+
+func BenchmarkKepler(b *testing.B) {
+	for _, s := range solvers {
+		b.Run(s.name, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				s.solve(.5, unit.Angle(i%628)*.01)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+// Public domain
+
+package astro
+
+// Mag: asteroid and comet magnitudes.
+
+import (
+	"math"
+
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/unit"
+)
+
+// cosPhase returns the cosine of the phase angle from observer-object and
+// sun-object vectors and their lengths.
+func cosPhase(oov, sov *coord.Cart, ood, sod float64) float64 {
+	return oov.Dot(sov) / (ood * sod)
+}
+
+// clampCos clamps the cosine of a phase angle to the range -1 to 1, as
+// rounding can take it outside, for example at zero phase.
+func clampCos(cosβ float64) float64 {
+	return math.Max(-1, math.Min(1, cosβ))
+}
+
+// phaseAngle returns the phase angle for its cosine.
+func phaseAngle(cosβ float64) unit.Angle {
+	return unit.Angle(math.Acos(clampCos(cosβ)))
+}
+
+// phaseHG returns the H-G phase function (1-G)Φ1 + GΦ2 for the cosine of
+// phase angle.
+func phaseHG(cosβ, G float64) float64 {
+	cosβ = clampCos(cosβ)
+	tanhalf := math.Sqrt(1-cosβ*cosβ) / (1 + cosβ)
+	phi1 := math.Exp(-3.33 * math.Pow(tanhalf, 0.63))
+	phi2 := math.Exp(-1.87 * math.Pow(tanhalf, 1.22))
+	return (1-G)*phi1 + G*phi2
+}
+
+// VMag computes predicted V magnitude from absolute magnitude H and slope
+// parameter G by the H-G system.
+//
+// Arguments r and Δ are the sun-object and observer-object distances in AU,
+// β is the phase angle.
+func VMag(H, G, r, Δ float64, β unit.Angle) float64 {
+	return H + 5*math.Log10(r*Δ) - 2.5*math.Log10(phaseHG(β.Cos(), G))
+}
+
+// HMagG computes H from V magnitude by the H-G system with slope
+// parameter G.
+//
+// Arguments oov and sov are observer-object and sun-object vectors, ood and
+// sod are their lengths.  HMag is HMagG with G = .15.
+func HMagG(oov, sov *coord.Cart, vmag, ood, sod, G float64) float64 {
+	cospsi := cosPhase(oov, sov, ood, sod)
+	if cospsi < -.9999 {
+		// object is straight into the sun.  doesn't seem too likely,
+		// but anyway, this returns a valid value.
+		return 30
+	}
+	return vmag -
+		5.*math.Log10(ood*sod) +
+		2.5*math.Log10(phaseHG(cospsi, G))
+}
+
+// VMagG1G2 computes predicted V magnitude from absolute magnitude H and
+// slope parameters G1 and G2 by the H, G1, G2 system.
+//
+// Arguments r and Δ are the sun-object and observer-object distances in AU,
+// β is the phase angle.
+//
+// The system is that of Muinonen et al., Icarus 209, 542 (2010).  The phase
+// function is defined for phase angles up to 150°.
+func VMagG1G2(H, G1, G2, r, Δ float64, β unit.Angle) float64 {
+	return H + 5*math.Log10(r*Δ) - 2.5*math.Log10(phaseG1G2(β, G1, G2))
+}
+
+// HMagG1G2 computes H from V magnitude by the H, G1, G2 system.
+//
+// Arguments are as for HMagG, with slope parameters G1 and G2.
+func HMagG1G2(oov, sov *coord.Cart, vmag, ood, sod, G1, G2 float64) float64 {
+	cospsi := cosPhase(oov, sov, ood, sod)
+	if cospsi < -.9999 {
+		return 30
+	}
+	β := phaseAngle(cospsi)
+	return vmag -
+		5.*math.Log10(ood*sod) +
+		2.5*math.Log10(phaseG1G2(β, G1, G2))
+}
+
+// phaseG1G2 returns the phase function G1Φ1 + G2Φ2 + (1-G1-G2)Φ3.
+func phaseG1G2(β unit.Angle, G1, G2 float64) float64 {
+	α := math.Abs(β.Rad())
+	var φ1, φ2, φ3 float64
+	if α < 7.5*math.Pi/180 {
+		φ1 = 1 - 6*α/math.Pi
+		φ2 = 1 - 9*α/(5*math.Pi)
+	} else {
+		φ1 = basisΦ1.eval(α)
+		φ2 = basisΦ2.eval(α)
+	}
+	if α < 30*math.Pi/180 {
+		φ3 = basisΦ3.eval(α)
+	}
+	return G1*φ1 + G2*φ2 + (1-G1-G2)*φ3
+}
+
+// basis functions of the H, G1, G2 system, as cubic splines with nodes
+// and end point derivatives from Muinonen et al. table 1.
+var (
+	basisΦ1 = newSpline(
+		[]float64{7.5, 30, 60, 90, 120, 150},
+		[]float64{7.5e-1, 3.3486016e-1, 1.3410560e-1, 5.1104756e-2,
+			2.1465687e-2, 3.6396989e-3},
+		-1.9098593, -9.1328612e-2)
+	basisΦ2 = newSpline(
+		[]float64{7.5, 30, 60, 90, 120, 150},
+		[]float64{9.25e-1, 6.2884169e-1, 3.1755495e-1, 1.2716367e-1,
+			2.2373903e-2, 1.6505689e-4},
+		-5.7295780e-1, -8.6573138e-8)
+	basisΦ3 = newSpline(
+		[]float64{0, .3, 1, 2, 4, 8, 12, 20, 30},
+		[]float64{1, 8.3381185e-1, 5.7735424e-1, 4.2144772e-1,
+			2.3174230e-1, 1.0348178e-1, 6.1733473e-2, 1.6107006e-2, 0},
+		-1.0630097e-1, 0)
+)
+
+// spline is a cubic spline with specified end point first derivatives.
+type spline struct {
+	x, y, y2 []float64
+}
+
+// newSpline constructs a spline from nodes x in degrees, values y, and
+// first derivatives (per radian) d0 and dn at the end points.
+func newSpline(x, y []float64, d0, dn float64) *spline {
+	n := len(x)
+	s := &spline{make([]float64, n), y, make([]float64, n)}
+	for i, d := range x {
+		s.x[i] = d * math.Pi / 180
+	}
+	x = s.x
+	// tridiagonal algorithm for second derivatives
+	u := make([]float64, n)
+	s.y2[0] = -.5
+	u[0] = 3 / (x[1] - x[0]) * ((y[1]-y[0])/(x[1]-x[0]) - d0)
+	for i := 1; i < n-1; i++ {
+		σ := (x[i] - x[i-1]) / (x[i+1] - x[i-1])
+		p := σ*s.y2[i-1] + 2
+		s.y2[i] = (σ - 1) / p
+		u[i] = (y[i+1]-y[i])/(x[i+1]-x[i]) - (y[i]-y[i-1])/(x[i]-x[i-1])
+		u[i] = (6*u[i]/(x[i+1]-x[i-1]) - σ*u[i-1]) / p
+	}
+	un := 3 / (x[n-1] - x[n-2]) * (dn - (y[n-1]-y[n-2])/(x[n-1]-x[n-2]))
+	s.y2[n-1] = (un - .5*u[n-2]) / (.5*s.y2[n-2] + 1)
+	for k := n - 2; k >= 0; k-- {
+		s.y2[k] = s.y2[k]*s.y2[k+1] + u[k]
+	}
+	return s
+}
+
+// eval evaluates the spline at x.  Values outside the node range are
+// extrapolated from the end intervals.
+func (s *spline) eval(x float64) float64 {
+	k := 1
+	for k < len(s.x)-1 && x > s.x[k] {
+		k++
+	}
+	h := s.x[k] - s.x[k-1]
+	a := (s.x[k] - x) / h
+	b := (x - s.x[k-1]) / h
+	return a*s.y[k-1] + b*s.y[k] +
+		((a*a*a-a)*s.y2[k-1]+(b*b*b-b)*s.y2[k])*h*h/6
+}
+
+// NuclearPhaseCoeff is the phase coefficient used for comet nuclear
+// magnitudes, in magnitudes per degree of phase angle.
+const NuclearPhaseCoeff = .035
+
+// CometTotalMag computes predicted total magnitude of a comet from total
+// absolute magnitude M1 and slope parameter K1.
+//
+// Arguments r and Δ are the sun-object and observer-object distances in AU.
+// The magnitude is m1 = M1 + 5 log Δ + K1 log r.
+func CometTotalMag(M1, K1, r, Δ float64) float64 {
+	return M1 + 5*math.Log10(Δ) + K1*math.Log10(r)
+}
+
+// CometNuclearMag computes predicted nuclear magnitude of a comet from
+// nuclear absolute magnitude M2 and slope parameter K2.
+//
+// Arguments r and Δ are the sun-object and observer-object distances in AU,
+// β is the phase angle.  The magnitude is
+// m2 = M2 + 5 log Δ + K2 log r + NuclearPhaseCoeff β, with β in degrees.
+func CometNuclearMag(M2, K2, r, Δ float64, β unit.Angle) float64 {
+	return M2 + 5*math.Log10(Δ) + K2*math.Log10(r) +
+		NuclearPhaseCoeff*math.Abs(β.Deg())
+}
+
+// CometM1 computes total absolute magnitude M1 from an observed total
+// magnitude.
+//
+// Arguments ood and sod are observer-object and sun-object distances, as
+// for CometM2; K1 is the slope parameter.  The total magnitude has no phase
+// term, so the vectors are not needed.
+func CometM1(mag, ood, sod, K1 float64) float64 {
+	return mag - 5*math.Log10(ood) - K1*math.Log10(sod)
+}
+
+// CometM2 computes nuclear absolute magnitude M2 from an observed nuclear
+// magnitude.
+//
+// Arguments oov and sov are observer-object and sun-object vectors, ood and
+// sod are their lengths, as for HMag; K2 is the slope parameter.
+func CometM2(oov, sov *coord.Cart, mag, ood, sod, K2 float64) float64 {
+	β := phaseAngle(cosPhase(oov, sov, ood, sod))
+	return mag - 5*math.Log10(ood) - K2*math.Log10(sod) -
+		NuclearPhaseCoeff*β.Deg()
+}
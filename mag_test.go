@@ -0,0 +1,155 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/unit"
+)
+
+func ExampleVMag() {
+	// inverse of ExampleHMag
+	fmt.Printf("V = %.1f\n", astro.VMag(21.5, .15, 1, .5, 0))
+	// Output:
+	// V = 20.0
+}
+
+func ExampleHMagG() {
+	oov := &coord.Cart{X: .5, Y: .3}
+	sov := &coord.Cart{X: 1, Y: .3}
+	ood := math.Sqrt(oov.Square())
+	sod := math.Sqrt(sov.Square())
+	for _, G := range []float64{.05, .15, .25} {
+		fmt.Printf("G = %.2f  H = %.2f\n",
+			G, astro.HMagG(oov, sov, 20, ood, sod, G))
+	}
+	// Output:
+	// G = 0.05  H = 20.16
+	// G = 0.15  H = 20.27
+	// G = 0.25  H = 20.37
+}
+
+func TestVMag(t *testing.T) {
+	// VMag and HMagG should be inverses.
+	oov := &coord.Cart{X: 1.2, Y: -.4, Z: .3}
+	sov := &coord.Cart{X: 2.1, Y: .2, Z: .1}
+	ood := math.Sqrt(oov.Square())
+	sod := math.Sqrt(sov.Square())
+	β := unit.Angle(math.Acos(oov.Dot(sov) / (ood * sod)))
+	for _, G := range []float64{-.1, .15, .4} {
+		H := astro.HMagG(oov, sov, 18.7, ood, sod, G)
+		if v := astro.VMag(H, G, sod, ood, β); math.Abs(v-18.7) > 1e-12 {
+			t.Errorf("G %g: VMag(HMagG) = %f", G, v)
+		}
+	}
+	if h, hg := astro.HMag(oov, sov, 18.7, ood, sod),
+		astro.HMagG(oov, sov, 18.7, ood, sod, .15); h != hg {
+		t.Errorf("HMag %f, HMagG(.15) %f", h, hg)
+	}
+	for _, g := range [][2]float64{{.3, .4}, {.8, .1}, {0, 0}} {
+		H := astro.HMagG1G2(oov, sov, 18.7, ood, sod, g[0], g[1])
+		v := astro.VMagG1G2(H, g[0], g[1], sod, ood, β)
+		if math.Abs(v-18.7) > 1e-12 {
+			t.Errorf("G1, G2 %g: VMagG1G2(HMagG1G2) = %f", g, v)
+		}
+	}
+}
+
+func TestG1G2Basis(t *testing.T) {
+	// Φ1, Φ2, Φ3 individually, by unit G1, G2.  Values at nodes of
+	// Muinonen et al. table 1.
+	φ := func(deg, G1, G2 float64) float64 {
+		v := astro.VMagG1G2(0, G1, G2, 1, 1, unit.AngleFromDeg(deg))
+		return math.Pow(10, -.4*v)
+	}
+	for _, c := range []struct {
+		deg, G1, G2, want float64
+	}{
+		{0, 1, 0, 1},
+		{0, 0, 1, 1},
+		{0, 0, 0, 1},
+		{7.5, 1, 0, .75},
+		{7.5, 0, 1, .925},
+		{60, 1, 0, 1.3410560e-1},
+		{120, 0, 1, 2.2373903e-2},
+		{4, 0, 0, 2.3174230e-1},
+		{12, 0, 0, 6.1733473e-2},
+	} {
+		if got := φ(c.deg, c.G1, c.G2); math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("α %g° G1 %g G2 %g: got %.9f want %.9f",
+				c.deg, c.G1, c.G2, got, c.want)
+		}
+	}
+	// functions should decrease monotonically
+	last := [3]float64{2, 2, 2}
+	for deg := 0.; deg <= 150; deg += .1 {
+		f := [3]float64{φ(deg, 1, 0), φ(deg, 0, 1), 0}
+		if deg < 30 {
+			f[2] = φ(deg, 0, 0)
+		}
+		for i := range f {
+			if f[i] > last[i] || f[i] < 0 {
+				t.Fatalf("Φ%d not monotonic at %g°", i+1, deg)
+			}
+		}
+		last = f
+	}
+}
+
+func TestHMagG1G2ZeroPhase(t *testing.T) {
+	// distances rounded short make the phase cosine exceed 1
+	v := &coord.Cart{X: .3, Y: .4, Z: 1.2}
+	d := math.Sqrt(v.Square()) * (1 - 1e-15)
+	if H := astro.HMagG1G2(v, v, 15, d, d, .6, .3); math.IsNaN(H) {
+		t.Error("HMagG1G2 NaN at zero phase")
+	}
+}
+
+func TestHMagGZeroPhase(t *testing.T) {
+	v := &coord.Cart{X: .3, Y: .4, Z: 1.2}
+	d := math.Sqrt(v.Square()) * (1 - 1e-15)
+	if H := astro.HMagG(v, v, 15, d, d, .15); math.IsNaN(H) {
+		t.Error("HMagG NaN at zero phase")
+	}
+	if H := astro.HMag(v, v, 15, d, d); math.IsNaN(H) {
+		t.Error("HMag NaN at zero phase")
+	}
+}
+
+func ExampleCometTotalMag() {
+	// M1 = 5.5, K1 = 10 at r = 2 AU, Δ = 1.5 AU.
+	fmt.Printf("m1 = %.2f\n", astro.CometTotalMag(5.5, 10, 2, 1.5))
+	// Output:
+	// m1 = 9.39
+}
+
+func TestCometMag(t *testing.T) {
+	oov := &coord.Cart{X: 1.2, Y: -.4, Z: .3}
+	sov := &coord.Cart{X: 2.1, Y: .2, Z: .1}
+	ood := math.Sqrt(oov.Square())
+	sod := math.Sqrt(sov.Square())
+	β := unit.Angle(math.Acos(oov.Dot(sov) / (ood * sod)))
+	M1 := astro.CometM1(12.3, ood, sod, 8)
+	if m := astro.CometTotalMag(M1, 8, sod, ood); math.Abs(m-12.3) > 1e-12 {
+		t.Errorf("CometTotalMag(CometM1) = %f", m)
+	}
+	M2 := astro.CometM2(oov, sov, 15.1, ood, sod, 5)
+	if m := astro.CometNuclearMag(M2, 5, sod, ood, β); math.Abs(m-15.1) > 1e-12 {
+		t.Errorf("CometNuclearMag(CometM2) = %f", m)
+	}
+	// zero phase, with distances rounded short
+	d := ood * (1 - 1e-15)
+	if M := astro.CometM2(oov, oov, 15.1, d, d, 5); math.IsNaN(M) {
+		t.Error("CometM2 NaN at zero phase")
+	}
+	// nuclear magnitude fades with phase angle
+	if astro.CometNuclearMag(M2, 5, sod, ood, β) <=
+		astro.CometNuclearMag(M2, 5, sod, ood, 0) {
+		t.Error("no phase effect")
+	}
+}
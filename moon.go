@@ -0,0 +1,290 @@
+// Public domain
+
+package astro
+
+// Moon: Chapter 47, Position of the Moon, and chapter 48, Illuminated
+// Fraction of the Moon's Disk.
+
+import (
+	"math"
+
+	"github.com/soniakeys/unit"
+)
+
+// MoonPosition returns geocentric location of the Moon.
+//
+// The theory is ELP-2000/82 as truncated by Meeus, chapter 47.  Accuracy is
+// about 10″ in longitude and 4″ in latitude.
+//
+// Results are referenced to the mean equinox of date and do not include
+// the effect of nutation.
+//
+//	λ  Geocentric longitude.
+//	β  Geocentric latitude.
+//	Δ  Distance between centers of the Earth and Moon, in km.
+func MoonPosition(jde float64) (λ, β unit.Angle, Δ float64) {
+	T := J2000Century(jde)
+	// (47.1) p. 338
+	Lp := unit.AngleFromDeg(Horner(T, 218.3164477, 481267.88123421,
+		-.0015786, 1./538841, -1./65194000))
+	D, M, Mp, F := moonArgs(T)
+	A1 := unit.AngleFromDeg(119.75 + 131.849*T)
+	A2 := unit.AngleFromDeg(53.09 + 479264.29*T)
+	A3 := unit.AngleFromDeg(313.45 + 481266.484*T)
+	// (47.6) p. 338
+	E := Horner(T, 1, -.002516, -.0000074)
+	E2 := E * E
+	// additive terms, p. 342
+	Σl := 3958*A1.Sin() + 1962*(Lp-F).Sin() + 318*A2.Sin()
+	Σr := 0.
+	Σb := -2235*Lp.Sin() + 382*A3.Sin() + 175*(A1-F).Sin() +
+		175*(A1+F).Sin() + 127*(Lp-Mp).Sin() - 115*(Lp+Mp).Sin()
+	for i := range table47A {
+		r := &table47A[i]
+		sa, ca := (r.d*D + r.m*M + r.mp*Mp + r.f*F).Sincos()
+		switch r.m {
+		case 0:
+			Σl += r.Σl * sa
+			Σr += r.Σr * ca
+		case 1, -1:
+			Σl += r.Σl * sa * E
+			Σr += r.Σr * ca * E
+		case 2, -2:
+			Σl += r.Σl * sa * E2
+			Σr += r.Σr * ca * E2
+		}
+	}
+	for i := range table47B {
+		r := &table47B[i]
+		sb := (r.d*D + r.m*M + r.mp*Mp + r.f*F).Sin()
+		switch r.m {
+		case 0:
+			Σb += r.Σb * sb
+		case 1, -1:
+			Σb += r.Σb * sb * E
+		case 2, -2:
+			Σb += r.Σb * sb * E2
+		}
+	}
+	λ = (Lp + unit.AngleFromDeg(Σl*1e-6)).Mod1()
+	β = unit.AngleFromDeg(Σb * 1e-6)
+	Δ = 385000.56 + Σr*1e-3
+	return
+}
+
+// moonArgs returns the fundamental arguments D, M, M′, F of the lunar
+// theory, (47.2-47.5) p. 338.
+func moonArgs(T float64) (D, M, Mp, F unit.Angle) {
+	D = unit.AngleFromDeg(Horner(T, 297.8501921, 445267.1114034,
+		-.0018819, 1./545868, -1./113065000))
+	M = unit.AngleFromDeg(Horner(T, 357.5291092, 35999.0502909,
+		-.0001535, 1./24490000))
+	Mp = unit.AngleFromDeg(Horner(T, 134.9633964, 477198.8675055,
+		.0087414, 1./69699, -1./14712000))
+	F = unit.AngleFromDeg(Horner(T, 93.272095, 483202.0175233,
+		-.0036539, -1./3526000, 1./863310000))
+	return
+}
+
+// MoonParallax returns equatorial horizontal parallax of the Moon.
+//
+// Argument Δ is distance between centers of the Earth and Moon, in km.
+func MoonParallax(Δ float64) unit.Angle {
+	// p. 337
+	return unit.Angle(math.Asin(6378.14 / Δ))
+}
+
+// MoonPhase returns the illuminated fraction of the Moon's disk, the phase
+// angle, and the position angle of the bright limb.
+//
+// Apparent places of the Moon from MoonPosition and of the Sun by the low
+// accuracy method of Meeus chapter 25 are used, corrected for nutation.
+//
+//	k  Illuminated fraction, 0 at new moon to 1 at full moon.
+//	i  Phase angle, the selenocentric elongation of the Earth from the Sun.
+//	χ  Position angle of the midpoint of the bright limb, measured from
+//	   the north point of the disk eastward.
+func MoonPhase(jde float64) (k float64, i, χ unit.Angle) {
+	λ, β, Δ := MoonPosition(jde)
+	λ0, R := solarApparent(jde)
+	R *= AU * 1e-3 // km
+	// (48.2) p. 345, and (48.3), with the Sun's latitude neglected.
+	cψ := β.Cos() * (λ - λ0).Cos()
+	sψ := math.Sqrt(1 - cψ*cψ)
+	i = unit.Angle(math.Atan2(R*sψ, Δ-R*cψ))
+	// (48.1) p. 345
+	k = (1 + i.Cos()) / 2
+	Δψ, Δε := Nutation(jde)
+	sε, cε := (MeanObliquity(jde) + Δε).Sincos()
+	α, δ := eclToEq(λ+Δψ, β, sε, cε)
+	α0, δ0 := eclToEq(λ0, 0, sε, cε)
+	// (48.5) p. 346
+	sδ0, cδ0 := δ0.Sincos()
+	sδ, cδ := δ.Sincos()
+	sα, cα := (α0 - α).Angle().Sincos()
+	χ = unit.Angle(math.Atan2(cδ0*sα, sδ0*cδ-cδ0*sδ*cα)).Mod1()
+	return
+}
+
+// Table 47.A, p. 339.  Coefficients of Σl in .000001°, Σr in .001 km.
+var table47A = [...]struct {
+	d, m, mp, f unit.Angle
+	Σl, Σr      float64
+}{
+	{0, 0, 1, 0, 6288774, -20905355},
+	{2, 0, -1, 0, 1274027, -3699111},
+	{2, 0, 0, 0, 658314, -2955968},
+	{0, 0, 2, 0, 213618, -569925},
+
+	{0, 1, 0, 0, -185116, 48888},
+	{0, 0, 0, 2, -114332, -3149},
+	{2, 0, -2, 0, 58793, 246158},
+	{2, -1, -1, 0, 57066, -152138},
+
+	{2, 0, 1, 0, 53322, -170733},
+	{2, -1, 0, 0, 45758, -204586},
+	{0, 1, -1, 0, -40923, -129620},
+	{1, 0, 0, 0, -34720, 108743},
+
+	{0, 1, 1, 0, -30383, 104755},
+	{2, 0, 0, -2, 15327, 10321},
+	{0, 0, 1, 2, -12528, 0},
+	{0, 0, 1, -2, 10980, 79661},
+
+	{4, 0, -1, 0, 10675, -34782},
+	{0, 0, 3, 0, 10034, -23210},
+	{4, 0, -2, 0, 8548, -21636},
+	{2, 1, -1, 0, -7888, 24208},
+
+	{2, 1, 0, 0, -6766, 30824},
+	{1, 0, -1, 0, -5163, -8379},
+	{1, 1, 0, 0, 4987, -16675},
+	{2, -1, 1, 0, 4036, -12831},
+
+	{2, 0, 2, 0, 3994, -10445},
+	{4, 0, 0, 0, 3861, -11650},
+	{2, 0, -3, 0, 3665, 14403},
+	{0, 1, -2, 0, -2689, -7003},
+
+	{2, 0, -1, 2, -2602, 0},
+	{2, -1, -2, 0, 2390, 10056},
+	{1, 0, 1, 0, -2348, 6322},
+	{2, -2, 0, 0, 2236, -9884},
+
+	{0, 1, 2, 0, -2120, 5751},
+	{0, 2, 0, 0, -2069, 0},
+	{2, -2, -1, 0, 2048, -4950},
+	{2, 0, 1, -2, -1773, 4130},
+
+	{2, 0, 0, 2, -1595, 0},
+	{4, -1, -1, 0, 1215, -3958},
+	{0, 0, 2, 2, -1110, 0},
+	{3, 0, -1, 0, -892, 3258},
+
+	{2, 1, 1, 0, -810, 2616},
+	{4, -1, -2, 0, 759, -1897},
+	{0, 2, -1, 0, -713, -2117},
+	{2, 2, -1, 0, -700, 2354},
+
+	{2, 1, -2, 0, 691, 0},
+	{2, -1, 0, -2, 596, 0},
+	{4, 0, 1, 0, 549, -1423},
+	{0, 0, 4, 0, 537, -1117},
+
+	{4, -1, 0, 0, 520, -1571},
+	{1, 0, -2, 0, -487, -1739},
+	{2, 1, 0, -2, -399, 0},
+	{0, 0, 2, -2, -381, -4421},
+
+	{1, 1, 1, 0, 351, 0},
+	{3, 0, -2, 0, -340, 0},
+	{4, 0, -3, 0, 330, 0},
+	{2, -1, 2, 0, 327, 0},
+
+	{0, 2, 1, 0, -323, 1165},
+	{1, 1, -1, 0, 299, 0},
+	{2, 0, 3, 0, 294, 0},
+	{2, 0, -1, -2, 0, 8752},
+}
+
+// Table 47.B, p. 341.  Coefficients of Σb in .000001°.
+var table47B = [...]struct {
+	d, m, mp, f unit.Angle
+	Σb          float64
+}{
+	{0, 0, 0, 1, 5128122},
+	{0, 0, 1, 1, 280602},
+	{0, 0, 1, -1, 277693},
+	{2, 0, 0, -1, 173237},
+
+	{2, 0, -1, 1, 55413},
+	{2, 0, -1, -1, 46271},
+	{2, 0, 0, 1, 32573},
+	{0, 0, 2, 1, 17198},
+
+	{2, 0, 1, -1, 9266},
+	{0, 0, 2, -1, 8822},
+	{2, -1, 0, -1, 8216},
+	{2, 0, -2, -1, 4324},
+
+	{2, 0, 1, 1, 4200},
+	{2, 1, 0, -1, -3359},
+	{2, -1, -1, 1, 2463},
+	{2, -1, 0, 1, 2211},
+
+	{2, -1, -1, -1, 2065},
+	{0, 1, -1, -1, -1870},
+	{4, 0, -1, -1, 1828},
+	{0, 1, 0, 1, -1794},
+
+	{0, 0, 0, 3, -1749},
+	{0, 1, -1, 1, -1565},
+	{1, 0, 0, 1, -1491},
+	{0, 1, 1, 1, -1475},
+
+	{0, 1, 1, -1, -1410},
+	{0, 1, 0, -1, -1344},
+	{1, 0, 0, -1, -1335},
+	{0, 0, 3, 1, 1107},
+
+	{4, 0, 0, -1, 1021},
+	{4, 0, -1, 1, 833},
+
+	{0, 0, 1, -3, 777},
+	{4, 0, -2, 1, 671},
+	{2, 0, 0, -3, 607},
+	{2, 0, 2, -1, 596},
+
+	{2, -1, 1, -1, 491},
+	{2, 0, -2, 1, -451},
+	{0, 0, 3, -1, 439},
+	{2, 0, 2, 1, 422},
+
+	{2, 0, -3, -1, 421},
+	{2, 1, -1, 1, -366},
+	{2, 1, 0, 1, -351},
+	{4, 0, 0, 1, 331},
+
+	{2, -1, 1, 1, 315},
+	{2, -2, 0, -1, 302},
+	{0, 0, 1, 3, -283},
+	{2, 1, 1, -1, -229},
+
+	{1, 1, 0, -1, 223},
+	{1, 1, 0, 1, 223},
+	{0, 1, -2, -1, -220},
+	{2, 1, -1, -1, -220},
+
+	{1, 0, 1, 1, -185},
+	{2, -1, -2, -1, 181},
+	{0, 1, 2, 1, -177},
+	{4, 0, -2, -1, 176},
+
+	{4, -1, -1, -1, 166},
+	{1, 0, 1, -1, -164},
+	{4, 0, 1, -1, 132},
+	{1, 0, -1, -1, -119},
+
+	{4, -1, 0, -1, 115},
+	{2, -2, 0, 1, 107},
+}
@@ -0,0 +1,50 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+)
+
+func ExampleMoonPosition() {
+	// Example 47.a, p. 342.
+	λ, β, Δ := astro.MoonPosition(2448724.5)
+	fmt.Printf("λ = %.6f\n", λ.Deg())
+	fmt.Printf("β = %.6f\n", β.Deg())
+	fmt.Printf("Δ = %.1f\n", Δ)
+	fmt.Printf("π = %.6f\n", astro.MoonParallax(Δ).Deg())
+	// Output:
+	// λ = 133.162655
+	// β = -3.229126
+	// Δ = 368409.7
+	// π = 0.991990
+}
+
+func ExampleMoonPhase() {
+	// Example 48.a, p. 347.
+	k, i, χ := astro.MoonPhase(2448724.5)
+	fmt.Printf("k = %.3f\n", k)
+	fmt.Printf("i = %.2f\n", i.Deg())
+	fmt.Printf("χ = %.1f\n", χ.Deg())
+	// Output:
+	// k = 0.679
+	// i = 69.08
+	// χ = 285.0
+}
+
+func TestMoonPhase(t *testing.T) {
+	// new and full moons of January 2000
+	for _, c := range []struct{ jde, k float64 }{
+		{2451550.260, 0},
+		{2451564.695, 1},
+	} {
+		k, _, _ := astro.MoonPhase(c.jde)
+		if math.Abs(k-c.k) > .005 {
+			t.Errorf("jde %.2f: k = %.4f", c.jde, k)
+		}
+	}
+}
@@ -0,0 +1,327 @@
+// Public domain
+
+package astro
+
+// Nutation: Chapter 22, Nutation and the Obliquity of the Ecliptic.
+
+import (
+	"math"
+
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/unit"
+)
+
+// Nutation returns nutation in longitude (Δψ) and nutation in
+// obliquity (Δε) by the IAU 1980 theory, with terms < .0003″ neglected,
+// following Meeus chapter 22.
+//
+// Nutation is consistent with MeanObliquity and is used by the functions of
+// this package that compute apparent positions.  Nutation2000B is more
+// accurate, and is used by the 2000B variants of TrueObliquity, MeanToTrue,
+// and TrueToMean.
+func Nutation(jde float64) (Δψ, Δε unit.Angle) {
+	T := J2000Century(jde)
+	D := unit.AngleFromDeg(Horner(T,
+		297.85036, 445267.111480, -0.0019142, 1./189474))
+	M := unit.AngleFromDeg(Horner(T,
+		357.52772, 35999.050340, -0.0001603, -1./300000))
+	N := unit.AngleFromDeg(Horner(T,
+		134.96298, 477198.867398, 0.0086972, 1./56250))
+	F := unit.AngleFromDeg(Horner(T,
+		93.27191, 483202.017538, -0.0036825, 1./327270))
+	Ω := unit.AngleFromDeg(Horner(T,
+		125.04452, -1934.136261, 0.0020708, 1./450000))
+	// sum in reverse order to accumulate smaller terms first
+	var Δψs, Δεs float64
+	for i := len(table22A) - 1; i >= 0; i-- {
+		row := &table22A[i]
+		arg := row.d*D + row.m*M + row.n*N + row.f*F + row.ω*Ω
+		s, c := arg.Sincos()
+		Δψs += s * (row.s0 + row.s1*T)
+		Δεs += c * (row.c0 + row.c1*T)
+	}
+	return unit.AngleFromSec(Δψs * .0001), unit.AngleFromSec(Δεs * .0001)
+}
+
+// MeanObliquity returns mean obliquity (ε₀) of the ecliptic by the IAU 1980
+// polynomial, (22.2) p. 147.
+//
+// Accuracy is 1″ over 2000 years and 10″ over 4000 years around J2000.
+func MeanObliquity(jde float64) unit.Angle {
+	return unit.AngleFromSec(Horner(J2000Century(jde),
+		84381.448, -46.815, -0.00059, 0.001813))
+}
+
+// TrueObliquity returns true obliquity (ε) of the ecliptic, ε₀ + Δε, by
+// MeanObliquity and Nutation.
+func TrueObliquity(jde float64) unit.Angle {
+	_, Δε := Nutation(jde)
+	return MeanObliquity(jde) + Δε
+}
+
+// MeanToTrue rotates rectangular equatorial coordinates p from the mean
+// equator and equinox of jde to the true equator and equinox of jde.
+//
+// Nutation and obliquity are by Nutation and MeanObliquity.
+func MeanToTrue(p coord.Cart, jde float64) coord.Cart {
+	Δψ, Δε := Nutation(jde)
+	ε0 := MeanObliquity(jde)
+	return nutate(p, ε0, ε0+Δε, Δψ)
+}
+
+// TrueToMean rotates rectangular equatorial coordinates p from the true
+// equator and equinox of jde to the mean equator and equinox of jde,
+// the inverse of MeanToTrue.
+func TrueToMean(p coord.Cart, jde float64) coord.Cart {
+	Δψ, Δε := Nutation(jde)
+	ε0 := MeanObliquity(jde)
+	return nutate(p, ε0+Δε, ε0, -Δψ)
+}
+
+// nutate rotates p from the equator of obliquity εFrom to the ecliptic,
+// adds Δψ to longitude, then rotates to the equator of obliquity εTo.
+func nutate(p coord.Cart, εFrom, εTo, Δψ unit.Angle) coord.Cart {
+	s, c := εFrom.Sincos()
+	y := c*p.Y + s*p.Z
+	z := -s*p.Y + c*p.Z
+	s, c = Δψ.Sincos()
+	x := c*p.X - s*y
+	y = s*p.X + c*y
+	s, c = εTo.Sincos()
+	return coord.Cart{X: x, Y: c*y - s*z, Z: s*y + c*z}
+}
+
+// Table 22.A, p. 145.  Coefficients are in units of .0001″.
+var table22A = []struct {
+	d, m, n, f, ω  unit.Angle
+	s0, s1, c0, c1 float64
+}{
+	{0, 0, 0, 0, 1, -171996, -174.2, 92025, 8.9},
+	{-2, 0, 0, 2, 2, -13187, -1.6, 5736, -3.1},
+	{0, 0, 0, 2, 2, -2274, -0.2, 977, -0.5},
+	{0, 0, 0, 0, 2, 2062, 0.2, -895, 0.5},
+	{0, 1, 0, 0, 0, 1426, -3.4, 54, -0.1},
+	{0, 0, 1, 0, 0, 712, 0.1, -7, 0},
+	{-2, 1, 0, 2, 2, -517, 1.2, 224, -0.6},
+	{0, 0, 0, 2, 1, -386, -0.4, 200, 0},
+	{0, 0, 1, 2, 2, -301, 0, 129, -0.1},
+	{-2, -1, 0, 2, 2, 217, -0.5, -95, 0.3},
+	{-2, 0, 1, 0, 0, -158, 0, 0, 0},
+	{-2, 0, 0, 2, 1, 129, 0.1, -70, 0},
+	{0, 0, -1, 2, 2, 123, 0, -53, 0},
+	{2, 0, 0, 0, 0, 63, 0, 0, 0},
+	{0, 0, 1, 0, 1, 63, 0.1, -33, 0},
+	{2, 0, -1, 2, 2, -59, 0, 26, 0},
+	{0, 0, -1, 0, 1, -58, -0.1, 32, 0},
+	{0, 0, 1, 2, 1, -51, 0, 27, 0},
+	{-2, 0, 2, 0, 0, 48, 0, 0, 0},
+	{0, 0, -2, 2, 1, 46, 0, -24, 0},
+	{2, 0, 0, 2, 2, -38, 0, 16, 0},
+	{0, 0, 2, 2, 2, -31, 0, 13, 0},
+	{0, 0, 2, 0, 0, 29, 0, 0, 0},
+	{-2, 0, 1, 2, 2, 29, 0, -12, 0},
+	{0, 0, 0, 2, 0, 26, 0, 0, 0},
+	{-2, 0, 0, 2, 0, -22, 0, 0, 0},
+	{0, 0, -1, 2, 1, 21, 0, -10, 0},
+	{0, 2, 0, 0, 0, 17, -0.1, 0, 0},
+	{2, 0, -1, 0, 1, 16, 0, -8, 0},
+	{-2, 2, 0, 2, 2, -16, 0.1, 7, 0},
+	{0, 1, 0, 0, 1, -15, 0, 9, 0},
+	{-2, 0, 1, 0, 1, -13, 0, 7, 0},
+	{0, -1, 0, 0, 1, -12, 0, 6, 0},
+	{0, 0, 2, -2, 0, 11, 0, 0, 0},
+	{2, 0, -1, 2, 1, -10, 0, 5, 0},
+	{2, 0, 1, 2, 2, -8, 0, 3, 0},
+	{0, 1, 0, 2, 2, 7, 0, -3, 0},
+	{-2, 1, 1, 0, 0, -7, 0, 0, 0},
+	{0, -1, 0, 2, 2, -7, 0, 3, 0},
+	{2, 0, 0, 2, 1, -7, 0, 3, 0},
+	{2, 0, 1, 0, 0, 6, 0, 0, 0},
+	{-2, 0, 2, 2, 2, 6, 0, -3, 0},
+	{-2, 0, 1, 2, 1, 6, 0, -3, 0},
+	{2, 0, -2, 0, 1, -6, 0, 3, 0},
+	{2, 0, 0, 0, 1, -6, 0, 3, 0},
+	{0, -1, 1, 0, 0, 5, 0, 0, 0},
+	{-2, -1, 0, 2, 1, -5, 0, 3, 0},
+	{-2, 0, 0, 0, 1, -5, 0, 3, 0},
+	{0, 0, 2, 2, 1, -5, 0, 3, 0},
+	{-2, 0, 2, 0, 1, 4, 0, 0, 0},
+	{-2, 1, 0, 2, 1, 4, 0, 0, 0},
+	{0, 0, 1, -2, 0, 4, 0, 0, 0},
+	{-1, 0, 1, 0, 0, -4, 0, 0, 0},
+	{-2, 1, 0, 0, 0, -4, 0, 0, 0},
+	{1, 0, 0, 0, 0, -4, 0, 0, 0},
+	{0, 0, 1, 2, 0, 3, 0, 0, 0},
+	{0, 0, -2, 2, 2, -3, 0, 0, 0},
+	{-1, -1, 1, 0, 0, -3, 0, 0, 0},
+	{0, 1, 1, 0, 0, -3, 0, 0, 0},
+	{0, -1, 1, 2, 2, -3, 0, 0, 0},
+	{2, -1, -1, 2, 2, -3, 0, 0, 0},
+	{0, 0, 3, 2, 2, -3, 0, 0, 0},
+	{2, -1, 0, 2, 2, -3, 0, 0, 0},
+}
+
+// delaunay returns the Delaunay arguments l, l′, F, D, Ω of the IERS
+// Conventions 2003 for T in Julian centuries of TT since J2000.
+func delaunay(T float64) (l, lp, F, D, Ω unit.Angle) {
+	// arguments can be many revolutions.  reduce before conversion
+	// to radians to preserve precision.
+	arg := func(c ...float64) unit.Angle {
+		return unit.AngleFromSec(math.Mod(Horner(T, c...), 1296000))
+	}
+	l = arg(485868.249036, 1717915923.2178, 31.8792, 0.051635, -0.00024470)
+	lp = arg(1287104.793048, 129596581.0481, -0.5532, 0.000136, -0.00001149)
+	F = arg(335779.526232, 1739527262.8478, -12.7512, -0.001037, 0.00000417)
+	D = arg(1072260.703692, 1602961601.2090, -6.3706, 0.006593, -0.00003169)
+	Ω = arg(450160.398036, -6962890.5431, 7.4722, 0.007702, -0.00005939)
+	return
+}
+
+// Nutation2000B returns nutation in longitude (Δψ) and nutation in
+// obliquity (Δε) by the IAU 2000B model of McCarthy and Luzum, Celestial
+// Mechanics 85, 37 (2003).
+//
+// Accuracy is 1 milliarcsecond over 1995-2050.  Use with MeanObliquity2006.
+func Nutation2000B(jde float64) (Δψ, Δε unit.Angle) {
+	T := J2000Century(jde)
+	// the model takes the fundamental arguments as linear in T.
+	arg := func(c0, c1 float64) unit.Angle {
+		return unit.AngleFromSec(math.Mod(c0+c1*T, 1296000))
+	}
+	l := arg(485868.249036, 1717915923.2178)
+	lp := arg(1287104.79305, 129596581.0481)
+	F := arg(335779.526232, 1739527262.8478)
+	D := arg(1072260.70369, 1602961601.2090)
+	Ω := arg(450160.398036, -6962890.5431)
+	var Δψs, Δεs float64
+	for i := len(table2000B) - 1; i >= 0; i-- {
+		row := &table2000B[i]
+		arg := row.l*l + row.lp*lp + row.f*F + row.d*D + row.ω*Ω
+		s, c := arg.Sincos()
+		Δψs += (row.ps+row.pst*T)*s + row.pc*c
+		Δεs += (row.ec+row.ect*T)*c + row.es*s
+	}
+	// coefficients are in units of .1 µas.  fixed offsets replace the
+	// planetary terms.
+	return unit.AngleFromSec(Δψs*1e-7 - .135e-3),
+		unit.AngleFromSec(Δεs*1e-7 + .388e-3)
+}
+
+// MeanObliquity2006 returns mean obliquity (ε_A) of the ecliptic by the
+// IAU 2006 precession model.
+func MeanObliquity2006(jde float64) unit.Angle {
+	return unit.AngleFromSec(Horner(J2000Century(jde), 84381.406,
+		-46.836769, -0.0001831, 0.00200340, -0.000000576, -0.0000000434))
+}
+
+// TrueObliquity2000B returns true obliquity (ε) of the ecliptic, ε_A + Δε,
+// by MeanObliquity2006 and Nutation2000B.
+func TrueObliquity2000B(jde float64) unit.Angle {
+	_, Δε := Nutation2000B(jde)
+	return MeanObliquity2006(jde) + Δε
+}
+
+// MeanToTrue2000B rotates rectangular equatorial coordinates p from the mean
+// equator and equinox of jde to the true equator and equinox of jde, as
+// MeanToTrue but with nutation and obliquity by Nutation2000B and
+// MeanObliquity2006.
+func MeanToTrue2000B(p coord.Cart, jde float64) coord.Cart {
+	Δψ, Δε := Nutation2000B(jde)
+	εA := MeanObliquity2006(jde)
+	return nutate(p, εA, εA+Δε, Δψ)
+}
+
+// TrueToMean2000B rotates rectangular equatorial coordinates p from the true
+// equator and equinox of jde to the mean equator and equinox of jde,
+// the inverse of MeanToTrue2000B.
+func TrueToMean2000B(p coord.Cart, jde float64) coord.Cart {
+	Δψ, Δε := Nutation2000B(jde)
+	εA := MeanObliquity2006(jde)
+	return nutate(p, εA+Δε, εA, -Δψ)
+}
+
+// IAU 2000B luni-solar nutation series.  Multipliers of l, l′, F, D, Ω,
+// then longitude coefficients sin, t sin, cos and obliquity coefficients
+// cos, t cos, sin, in units of .1 µas.
+var table2000B = []struct {
+	l, lp, f, d, ω           unit.Angle
+	ps, pst, pc, ec, ect, es float64
+}{
+	{0, 0, 0, 0, 1, -172064161, -174666, 33386, 92052331, 9086, 15377},
+	{0, 0, 2, -2, 2, -13170906, -1675, -13696, 5730336, -3015, -4587},
+	{0, 0, 2, 0, 2, -2276413, -234, 2796, 978459, -485, 1374},
+	{0, 0, 0, 0, 2, 2074554, 207, -698, -897492, 470, -291},
+	{0, 1, 0, 0, 0, 1475877, -3633, 11817, 73871, -184, -1924},
+	{0, 1, 2, -2, 2, -516821, 1226, -524, 224386, -677, -174},
+	{1, 0, 0, 0, 0, 711159, 73, -872, -6750, 0, 358},
+	{0, 0, 2, 0, 1, -387298, -367, 380, 200728, 18, 318},
+	{1, 0, 2, 0, 2, -301461, -36, 816, 129025, -63, 367},
+	{0, -1, 2, -2, 2, 215829, -494, 111, -95929, 299, 132},
+	{0, 0, 2, -2, 1, 128227, 137, 181, -68982, -9, 39},
+	{-1, 0, 2, 0, 2, 123457, 11, 19, -53311, 32, -4},
+	{-1, 0, 0, 2, 0, 156994, 10, -168, -1235, 0, 82},
+	{1, 0, 0, 0, 1, 63110, 63, 27, -33228, 0, -9},
+	{-1, 0, 0, 0, 1, -57976, -63, -189, 31429, 0, -75},
+	{-1, 0, 2, 2, 2, -59641, -11, 149, 25543, -11, 66},
+	{1, 0, 2, 0, 1, -51613, -42, 129, 26366, 0, 78},
+	{-2, 0, 2, 0, 1, 45893, 50, 31, -24236, -10, 20},
+	{0, 0, 0, 2, 0, 63384, 11, -150, -1220, 0, 29},
+	{0, 0, 2, 2, 2, -38571, -1, 158, 16452, -11, 68},
+	{0, -2, 2, -2, 2, 32481, 0, 0, -13870, 0, 0},
+	{-2, 0, 0, 2, 0, -47722, 0, -18, 477, 0, -25},
+	{2, 0, 2, 0, 2, -31046, -1, 131, 13238, -11, 59},
+	{1, 0, 2, -2, 2, 28593, 0, -1, -12338, 10, -3},
+	{-1, 0, 2, 0, 1, 20441, 21, 10, -10758, 0, -3},
+	{2, 0, 0, 0, 0, 29243, 0, -74, -609, 0, 13},
+	{0, 0, 2, 0, 0, 25887, 0, -66, -550, 0, 11},
+	{0, 1, 0, 0, 1, -14053, -25, 79, 8551, -2, -45},
+	{-1, 0, 0, 2, 1, 15164, 10, 11, -8001, 0, -1},
+	{0, 2, 2, -2, 2, -15794, 72, -16, 6850, -42, -5},
+	{0, 0, -2, 2, 0, 21783, 0, 13, -167, 0, 13},
+	{1, 0, 0, -2, 1, -12873, -10, -37, 6953, 0, -14},
+	{0, -1, 0, 0, 1, -12654, 11, 63, 6415, 0, 26},
+	{-1, 0, 2, 2, 1, -10204, 0, 25, 5222, 0, 15},
+	{0, 2, 0, 0, 0, 16707, -85, -10, 168, -1, 10},
+	{1, 0, 2, 2, 2, -7691, 0, 44, 3268, 0, 19},
+	{-2, 0, 2, 0, 0, -11024, 0, -14, 104, 0, 2},
+	{0, 1, 2, 0, 2, 7566, -21, -11, -3250, 0, -5},
+	{0, 0, 2, 2, 1, -6637, -11, 25, 3353, 0, 14},
+	{0, -1, 2, 0, 2, -7141, 21, 8, 3070, 0, 4},
+	{0, 0, 0, 2, 1, -6302, -11, 2, 3272, 0, 4},
+	{1, 0, 2, -2, 1, 5800, 10, 2, -3045, 0, -1},
+	{2, 0, 2, -2, 2, 6443, 0, -7, -2768, 0, -4},
+	{-2, 0, 0, 2, 1, -5774, -11, -15, 3041, 0, -5},
+	{2, 0, 2, 0, 1, -5350, 0, 21, 2695, 0, 12},
+	{0, -1, 2, -2, 1, -4752, -11, -3, 2719, 0, -3},
+	{0, 0, 0, -2, 1, -4940, -11, -21, 2720, 0, -9},
+	{-1, -1, 0, 2, 0, 7350, 0, -8, -51, 0, 4},
+	{2, 0, 0, -2, 1, 4065, 0, 6, -2206, 0, 1},
+	{1, 0, 0, 2, 0, 6579, 0, -24, -199, 0, 2},
+	{0, 1, 2, -2, 1, 3579, 0, 5, -1900, 0, 1},
+	{1, -1, 0, 0, 0, 4725, 0, -6, -41, 0, 3},
+	{-2, 0, 2, 0, 2, -3075, 0, -2, 1313, 0, -1},
+	{3, 0, 2, 0, 2, -2904, 0, 15, 1233, 0, 7},
+	{0, -1, 0, 2, 0, 4348, 0, -10, -81, 0, 2},
+	{1, -1, 2, 0, 2, -2878, 0, 8, 1232, 0, 4},
+	{0, 0, 0, 1, 0, -4230, 0, 5, -20, 0, -2},
+	{-1, -1, 2, 2, 2, -2819, 0, 7, 1207, 0, 3},
+	{-1, 0, 2, 0, 0, -4056, 0, 5, 40, 0, -2},
+	{0, -1, 2, 2, 2, -2647, 0, 11, 1129, 0, 5},
+	{-2, 0, 0, 0, 1, -2294, 0, -10, 1266, 0, -4},
+	{1, 1, 2, 0, 2, 2481, 0, -7, -1062, 0, -3},
+	{2, 0, 0, 0, 1, 2179, 0, -2, -1129, 0, -2},
+	{-1, 1, 0, 1, 0, 3276, 0, 1, -9, 0, 0},
+	{1, 1, 0, 0, 0, -3389, 0, 5, 35, 0, -2},
+	{1, 0, 2, 0, 0, 3339, 0, -13, -107, 0, 1},
+	{-1, 0, 2, -2, 1, -1987, 0, -6, 1073, 0, -2},
+	{1, 0, 0, 0, 2, -1981, 0, 0, 854, 0, 0},
+	{-1, 0, 0, 1, 0, 4026, 0, -353, -553, 0, -139},
+	{0, 0, 2, 1, 2, 1660, 0, -5, -710, 0, -2},
+	{-1, 0, 2, 4, 2, -1521, 0, 9, 647, 0, 4},
+	{-1, 1, 0, 1, 1, 1314, 0, 0, -700, 0, 0},
+	{0, -2, 2, -2, 1, -1283, 0, 0, 672, 0, 0},
+	{1, 0, 2, 2, 1, -1331, 0, 8, 663, 0, 4},
+	{-2, 0, 2, 2, 2, 1383, 0, -2, -594, 0, -2},
+	{-1, 0, 0, 0, 2, 1405, 0, 4, -610, 0, 2},
+	{1, 1, 2, -2, 2, 1290, 0, 0, -556, 0, 0},
+}
@@ -0,0 +1,122 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/sexagesimal"
+	"github.com/soniakeys/unit"
+)
+
+func ExampleNutation() {
+	// Example 22.a, p. 148.
+	jde := 2446895.5
+	Δψ, Δε := astro.Nutation(jde)
+	ε0 := astro.MeanObliquity(jde)
+	ε := astro.TrueObliquity(jde)
+	fmt.Printf("Δψ = %+.3d\n", sexa.FmtAngle(Δψ))
+	fmt.Printf("Δε = %+.3d\n", sexa.FmtAngle(Δε))
+	fmt.Printf("ε₀ = %.3d\n", sexa.FmtAngle(ε0))
+	fmt.Printf("ε  = %.3d\n", sexa.FmtAngle(ε))
+	// Output:
+	// Δψ = -3″.788
+	// Δε = +9″.443
+	// ε₀ = 23°26′27″.407
+	// ε  = 23°26′36″.850
+}
+
+func TestNutation2000B(t *testing.T) {
+	// the two models agree to a few tens of milliarcseconds
+	for _, jde := range []float64{2446895.5, 2451545, 2458849.5} {
+		ψ1, ε1 := astro.Nutation(jde)
+		ψ2, ε2 := astro.Nutation2000B(jde)
+		if d := math.Abs((ψ1 - ψ2).Sec()); d > .05 {
+			t.Errorf("jde %.1f: Δψ differs by %.4f″", jde, d)
+		}
+		if d := math.Abs((ε1 - ε2).Sec()); d > .05 {
+			t.Errorf("jde %.1f: Δε differs by %.4f″", jde, d)
+		}
+	}
+}
+
+func TestMeanToTrue(t *testing.T) {
+	// mean place of θ Persei, Example 23.a, p. 152.
+	jde := 2462088.69
+	α := unit.AngleFromDeg(41.5599646)
+	δ := unit.AngleFromDeg(49.3520685)
+	sα, cα := α.Sincos()
+	sδ, cδ := δ.Sincos()
+	p := coord.Cart{X: cδ * cα, Y: cδ * sα, Z: sδ}
+	q := astro.MeanToTrue(p, jde)
+	Δα := unit.Angle(math.Atan2(q.Y, q.X)) - α
+	Δδ := unit.Angle(math.Asin(q.Z)) - δ
+	// (23.1) p. 151
+	Δψ, Δε := astro.Nutation(jde)
+	sε, cε := astro.TrueObliquity(jde).Sincos()
+	tδ := sδ / cδ
+	Δα1 := Δψ.Mul(cε+sε*sα*tδ) - Δε.Mul(cα*tδ)
+	Δδ1 := Δψ.Mul(sε*cα) + Δε.Mul(sα)
+	if d := math.Abs((Δα - Δα1).Sec()); d > .001 {
+		t.Errorf("Δα = %.4f″, (23.1) gives %.4f″", Δα.Sec(), Δα1.Sec())
+	}
+	if d := math.Abs((Δδ - Δδ1).Sec()); d > .001 {
+		t.Errorf("Δδ = %.4f″, (23.1) gives %.4f″", Δδ.Sec(), Δδ1.Sec())
+	}
+	r := astro.TrueToMean(q, jde)
+	if d := math.Abs(r.X-p.X) + math.Abs(r.Y-p.Y) + math.Abs(r.Z-p.Z); d > 1e-15 {
+		t.Error("round trip", d)
+	}
+}
+
+func TestNutation2000BSOFA(t *testing.T) {
+	// values from the SOFA test program, t_nut00b and t_obl06.
+	Δψ, Δε := astro.Nutation2000B(2400000.5 + 53736)
+	if d := math.Abs(Δψ.Rad() - -.9632552291148362783e-5); d > 1e-13 {
+		t.Errorf("Δψ = %.19e", Δψ.Rad())
+	}
+	if d := math.Abs(Δε.Rad() - .4063197106621159367e-4); d > 1e-13 {
+		t.Errorf("Δε = %.19e", Δε.Rad())
+	}
+	εA := astro.MeanObliquity2006(2400000.5 + 54388)
+	if d := math.Abs(εA.Rad() - .4090749229387258204); d > 1e-14 {
+		t.Errorf("ε_A = %.19f", εA.Rad())
+	}
+}
+
+func TestMeanToTrue2000B(t *testing.T) {
+	// nutation matrix from the SOFA test program, t_num00b.  iauNum00b
+	// takes mean obliquity from the IAU 1980 polynomial with the IAU 2000
+	// precession correction, which differs from MeanObliquity2006 by
+	// .04″, hence the tolerance.
+	jde := 2400000.5 + 53736
+	n := [3][3]float64{
+		{.9999999999536069682, .8837746144871248011e-5,
+			.3831488838252202945e-5},
+		{-.8837590456632304720e-5, .9999999991354692733,
+			-.4063198798559591654e-4},
+		{-.3831847930134941271e-5, .4063195412258168380e-4,
+			.9999999991671806225},
+	}
+	for j, p := range []coord.Cart{{X: 1}, {Y: 1}, {Z: 1}} {
+		q := astro.MeanToTrue2000B(p, jde)
+		if math.Abs(q.X-n[0][j]) > 1e-11 ||
+			math.Abs(q.Y-n[1][j]) > 1e-11 ||
+			math.Abs(q.Z-n[2][j]) > 1e-11 {
+			t.Errorf("column %d: got %v", j, q)
+		}
+		r := astro.TrueToMean2000B(q, jde)
+		if d := math.Abs(r.X-p.X) + math.Abs(r.Y-p.Y) + math.Abs(r.Z-p.Z); d > 1e-15 {
+			t.Error("round trip", d)
+		}
+	}
+	_, Δε := astro.Nutation2000B(jde)
+	ε := astro.TrueObliquity2000B(jde)
+	if d := ε - astro.MeanObliquity2006(jde) - Δε; math.Abs(d.Rad()) > 1e-15 {
+		t.Error("TrueObliquity2000B", ε)
+	}
+}
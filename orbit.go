@@ -3,12 +3,18 @@
 package astro
 
 import (
-	"errors"
 	"math"
 
+	"github.com/soniakeys/coord"
 	"github.com/soniakeys/unit"
 )
 
+// Elements holds Keplerian orbital elements, referenced to the ecliptic
+// and equinox of J2000.
+//
+// The size of an elliptic orbit (e < 1) is given by Axis.  The size of a
+// parabolic or hyperbolic orbit (e >= 1) is given by PDist.  Where Axis
+// is given for a hyperbolic orbit, it is negative.
 type Elements struct {
 	Axis  float64    // Semimajor axis, a, in AU
 	Ecc   float64    // Eccentricity, e
@@ -16,18 +22,41 @@ type Elements struct {
 	ArgP  unit.Angle // Argument of perihelion, ω
 	Node  unit.Angle // Longitude of ascending node, Ω
 	TimeP float64    // Time of perihelion, T, as jde
+	PDist float64    // Perihelion distance, q, in AU
 }
+
+// Orbit holds values precomputed from Elements for computing positions.
+//
+// Kepler may be set to select a solver for elliptic orbits.  If nil, a
+// default solver is used that combines KeplerNewton and KeplerBinary.
 type Orbit struct {
+	Kepler KeplerSolver
+
 	k          *Elements
 	n          unit.Angle // Angle/day
+	q          float64    // perihelion distance
 	_A, _B, _C unit.Angle
 	a, b, c    float64
 }
 
+// NewOrbit constructs an Orbit from Keplerian elements.
+//
+// For elliptic orbits, k.Axis must be valid.  For parabolic and hyperbolic
+// orbits, k.PDist must be valid.
 func NewOrbit(k *Elements) *Orbit {
-	o := &Orbit{
-		k: k,
-		n: unit.Angle(K / k.Axis / math.Sqrt(k.Axis)),
+	o := &Orbit{k: k}
+	switch e := k.Ecc; {
+	case e < 1:
+		o.q = k.Axis * (1 - e)
+		o.n = unit.Angle(K / k.Axis / math.Sqrt(k.Axis))
+	case e == 1:
+		// n here is the rate of W in Barker's equation, (34.1) p. 241
+		o.q = k.PDist
+		o.n = unit.Angle(3 * K / math.Sqrt(2*o.q*o.q*o.q))
+	default:
+		o.q = k.PDist
+		a := o.q / (e - 1) // magnitude of the (negative) semimajor axis
+		o.n = unit.Angle(K / a / math.Sqrt(a))
 	}
 	const sε = SOblJ2000
 	const cε = COblJ2000
@@ -50,11 +79,18 @@ func NewOrbit(k *Elements) *Orbit {
 	return o
 }
 
+// Position returns heliocentric rectangular equatorial coordinates for
+// the orbit at the given jde, referenced to the equinox of J2000.
+//
+// Results x, y, z, and r are in AU.
 func (o *Orbit) Position(jde float64) (x, y, z, r float64) {
-	M := o.n.Mul(jde - o.k.TimeP)
-	E := kepler(o.k.Ecc, M)
-	r = radius(E, o.k.Ecc, o.k.Axis)
-	ν := trueAnomaly(E, o.k.Ecc)
+	return o.position(jde - o.k.TimeP)
+}
+
+// position returns Position for dt days since perihelion.
+func (o *Orbit) position(dt float64) (x, y, z, r float64) {
+	var ν unit.Angle
+	ν, r = o.anomaly(dt)
 	// (33.9) p. 229
 	x = r * o.a * (o._A + o.k.ArgP + ν).Sin()
 	y = r * o.b * (o._B + o.k.ArgP + ν).Sin()
@@ -62,82 +98,154 @@ func (o *Orbit) Position(jde float64) (x, y, z, r float64) {
 	return
 }
 
-func kepler(e float64, M unit.Angle) unit.Angle {
-	if E, err := kepler2b(e, M, 15); err != nil {
-		return E
+// PositionVelocity returns heliocentric rectangular equatorial position and
+// velocity vectors for the orbit at the given jde, referenced to the
+// equinox of J2000.
+//
+// Position p is in AU, velocity v is in AU/day.  Note that AeiHv takes a
+// velocity scaled by the gravitational constant, that is, v divided by K.
+func (o *Orbit) PositionVelocity(jde float64) (p, v coord.Cart) {
+	return o.positionVelocity(jde - o.k.TimeP)
+}
+
+// positionVelocity returns PositionVelocity for dt days since perihelion.
+func (o *Orbit) positionVelocity(dt float64) (p, v coord.Cart) {
+	ν, r := o.anomaly(dt)
+	e := o.k.Ecc
+	// from conservation of angular momentum h = k√(q(1+e)) = r²dν/dt,
+	// and the derivative of r = q(1+e) / (1+e cos ν).
+	hp := K / math.Sqrt(o.q*(1+e))
+	sν, cν := ν.Sincos()
+	rd := hp * e * sν      // dr/dt
+	rνd := hp * (1 + e*cν) // r dν/dt
+	f := func(a float64, A unit.Angle) (x, xd float64) {
+		s, c := (A + o.k.ArgP + ν).Sincos()
+		return r * a * s, a * (rd*s + rνd*c)
 	}
-	return kepler3(e, M)
+	p.X, v.X = f(o.a, o._A)
+	p.Y, v.Y = f(o.b, o._B)
+	p.Z, v.Z = f(o.c, o._C)
+	return
 }
 
-// Kepler2b solves Kepler's equation by iteration.
+// ElementsFromState solves Keplerian elements from state vectors.
 //
-// The iterated formula is the same as in Kepler2 but a (different) limiting
-// function avoids divergence.
+// Arguments p and v are heliocentric rectangular equatorial position and
+// velocity, referenced to the equinox of J2000, in AU and AU/day, as
+// returned by Orbit.PositionVelocity.  Argument jde is the epoch of the
+// state vectors.
 //
-// Argument e is eccentricity, M is mean anomaly in radians,
-// places is the desired number of decimal places in the result.
+// Unlike AeiHv, all orbit types are handled.  PDist is always set.  Axis is
+// set for elliptic and hyperbolic orbits and is +Inf for a parabolic orbit.
+// An orbit with e within 1e-8 of 1 is returned as parabolic, with Ecc
+// exactly 1.
 //
-// Result E is eccentric anomaly in radians.
-func kepler2b(e float64, M unit.Angle, places int) (E unit.Angle, err error) {
-	f := func(E0 float64) float64 {
-		se, ce := math.Sincos(E0)
-		d := (M.Rad() + e*se - E0) / (1 - e*ce)
-		// method of Steele, Meeus p. 205
-		if d > .5 {
-			d = .5
-		} else if d < -.5 {
-			d = -.5
-		}
-		return E0 + d
+// Elements are ill-defined for circular and equatorial orbits.  For a
+// circular orbit (e < 1e-11) the result has ArgP = 0, with perihelion
+// placed at the ascending node.  For an equatorial orbit (i within 1e-11
+// radian of 0 or π) the result has Node = 0 so that ArgP is the longitude
+// of perihelion.
+func ElementsFromState(p, v *coord.Cart, jde float64) *Elements {
+	const tol = 1e-11
+	const sε = SOblJ2000
+	const cε = COblJ2000
+	// rotate to ecliptic
+	r := coord.Cart{X: p.X, Y: cε*p.Y + sε*p.Z, Z: -sε*p.Y + cε*p.Z}
+	rd := coord.Cart{X: v.X, Y: cε*v.Y + sε*v.Z, Z: -sε*v.Y + cε*v.Z}
+	rm := math.Sqrt(r.Square())
+	var h coord.Cart
+	h.Cross(&r, &rd)
+	hm := math.Sqrt(h.Square())
+	// eccentricity vector
+	vsq := rd.Square()
+	rv := r.Dot(&rd)
+	ev := coord.Cart{
+		X: ((vsq-U/rm)*r.X - rv*rd.X) / U,
+		Y: ((vsq-U/rm)*r.Y - rv*rd.Y) / U,
+		Z: ((vsq-U/rm)*r.Z - rv*rd.Z) / U,
 	}
-	ea, err := iterateDecimalPlaces(f, M.Rad(), places, places)
-	return unit.Angle(ea), err
-}
+	k := &Elements{Ecc: math.Sqrt(ev.Square())}
+	hxy := math.Hypot(h.X, h.Y)
+	k.Inc = unit.Angle(math.Atan2(hxy, h.Z))
 
-// Kepler3 solves Kepler's equation by binary search.
-//
-// Argument e is eccentricity, M is mean anomaly in radians.
-//
-// Result E is eccentric anomaly in radians.
-func kepler3(e float64, M unit.Angle) (E unit.Angle) {
-	// adapted from BASIC, Meeus p. 206
-	MR := M.Mod1().Rad()
-	f := 1
-	if MR > math.Pi {
-		f = -1
-		MR = 2*math.Pi - MR
+	// unit vector toward the ascending node, or the equinox if equatorial
+	n := coord.Cart{X: 1}
+	if hxy > tol*hm {
+		n = coord.Cart{X: -h.Y / hxy, Y: h.X / hxy}
+		k.Node = unit.Angle(math.Atan2(n.Y, n.X)).Mod1()
 	}
-	E0 := math.Pi * .5
-	d := math.Pi * .25
-	for i := 0; i < 53; i++ {
-		M1 := E0 - e*math.Sin(E0)
-		if MR-M1 < 0 {
-			E0 -= d
-		} else {
-			E0 += d
-		}
-		d *= .5
+	// unit vector toward perihelion, or the node if circular
+	pv := n
+	if k.Ecc > tol {
+		pv = coord.Cart{X: ev.X / k.Ecc, Y: ev.Y / k.Ecc, Z: ev.Z / k.Ecc}
 	}
-	if f < 0 {
-		E0 = -E0
+	// angle from a to b in the orbit plane, in the direction of motion
+	angle := func(a, b *coord.Cart) unit.Angle {
+		var c coord.Cart
+		c.Cross(a, b)
+		return unit.Angle(math.Atan2(c.Dot(&h)/hm, a.Dot(b)))
 	}
-	return unit.Angle(E0)
+	k.ArgP = angle(&n, &pv).Mod1()
+	ν := angle(&pv, &r)
+
+	e := k.Ecc
+	if math.Abs(e-1) < 1e-8 {
+		e = 1
+		k.Ecc = 1
+	}
+	k.PDist = hm * hm / (U * (1 + e))
+	switch {
+	case e < 1:
+		k.Axis = k.PDist / (1 - e)
+		E := 2 * math.Atan(math.Sqrt((1-e)/(1+e))*ν.Mul(.5).Tan())
+		M := E - e*math.Sin(E)
+		k.TimeP = jde - M*k.Axis*math.Sqrt(k.Axis)/K
+	case e == 1:
+		k.Axis = math.Inf(1)
+		s := ν.Mul(.5).Tan()
+		k.TimeP = jde - math.Sqrt(2*k.PDist*k.PDist*k.PDist)/K*(s+s*s*s/3)
+	default:
+		k.Axis = k.PDist / (1 - e)
+		H := 2 * math.Atanh(math.Sqrt((e-1)/(e+1))*ν.Mul(.5).Tan())
+		M := e*math.Sinh(H) - H
+		k.TimeP = jde + M*k.Axis*math.Sqrt(-k.Axis)/K
+	}
+	return k
 }
 
-// DecimalPlaces iterates to a fixed number of decimal places.
-//
-// Inputs are an improvement function, a starting value, the number of
-// decimal places desired in the result, and an iteration limit.
-func iterateDecimalPlaces(better func(float64) float64, start float64, places, maxIterations int) (float64, error) {
-	d := math.Pow(10, float64(-places))
-	for i := 0; i < maxIterations; i++ {
-		n := better(start)
-		if math.Abs(n-start) < d {
-			return n, nil
+// anomaly returns true anomaly ν and radius r at dt days since perihelion.
+func (o *Orbit) anomaly(dt float64) (ν unit.Angle, r float64) {
+	e := o.k.Ecc
+	switch {
+	case e < 1:
+		M := o.n.Mul(dt)
+		solve := o.Kepler
+		if solve == nil {
+			solve = kepler
 		}
-		start = n
+		E, _, _ := solve(e, M)
+		return trueAnomaly(E, e), radius(E, e, o.k.Axis)
+	case e == 1:
+		s := barker(o.n.Mul(dt).Rad())
+		// p. 241
+		return unit.Angle(2 * math.Atan(s)), o.q * (1 + s*s)
+	}
+	H := keplerHyperbolic(e, o.n.Mul(dt).Rad())
+	ν = unit.Angle(2 * math.Atan(math.Sqrt((e+1)/(e-1))*math.Tanh(H*.5)))
+	return ν, o.q / (e - 1) * (e*math.Cosh(H) - 1)
+}
+
+// barker solves Barker's equation s³ + 3s = W for s = tan(ν/2).
+func barker(W float64) float64 {
+	// closed form solution, Meeus p. 242.  Solving for positive W and
+	// restoring the sign avoids loss of precision in Y - 1/Y.
+	G := math.Abs(W) * .5
+	Y := math.Cbrt(G + math.Sqrt(G*G+1))
+	s := Y - 1/Y
+	if W < 0 {
+		s = -s
 	}
-	return 0, errors.New("Maximum iterations reached")
+	return s
 }
 
 // True returns true anomaly ν for given eccentric anomaly E.
@@ -0,0 +1,193 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/unit"
+)
+
+// near-parabolic elliptic, parabolic, and hyperbolic orbits with the same
+// perihelion distance should give nearly the same positions.
+func TestNearParabolic(t *testing.T) {
+	const q = .914
+	k := astro.Elements{
+		Inc:   unit.AngleFromDeg(89.4),
+		ArgP:  unit.AngleFromDeg(130.6),
+		Node:  unit.AngleFromDeg(282.5),
+		TimeP: 2450539.6,
+		PDist: q,
+	}
+	pe := k
+	pe.Ecc = 1
+	ph := k
+	ph.Ecc = 1 + 1e-8
+	pl := k
+	pl.Ecc = 1 - 1e-8
+	pl.Axis = q / (1 - pl.Ecc)
+	op := astro.NewOrbit(&pe)
+	oh := astro.NewOrbit(&ph)
+	for _, dt := range []float64{-200, -30, -1, 0, .5, 10, 60, 365} {
+		jde := k.TimeP + dt
+		x, y, z, r := op.Position(jde)
+		if d := math.Abs(math.Sqrt(x*x+y*y+z*z) - r); d > 1e-9*r {
+			t.Errorf("dt %g: parabolic r inconsistent by %g", dt, d)
+		}
+		if dt == 0 && math.Abs(r-q) > 1e-12 {
+			t.Errorf("parabolic r at perihelion = %f, want %f", r, q)
+		}
+		xh, yh, zh, rh := oh.Position(jde)
+		if d := math.Abs(xh-x) + math.Abs(yh-y) + math.Abs(zh-z) +
+			math.Abs(rh-r); d > 1e-6 {
+			t.Errorf("dt %g: hyperbolic differs from parabolic by %g", dt, d)
+		}
+	}
+	// elliptic case only for small dt where the mean anomaly remains
+	// resolvable.
+	ol := astro.NewOrbit(&pl)
+	_, _, _, r := op.Position(k.TimeP + 1)
+	_, _, _, rl := ol.Position(k.TimeP + 1)
+	if math.Abs(rl-r) > 1e-6 {
+		t.Errorf("elliptic r = %f, parabolic r = %f", rl, r)
+	}
+}
+
+func TestHyperbolic(t *testing.T) {
+	// perihelion distance and eccentricity similar to 1I/ʻOumuamua
+	k := &astro.Elements{
+		Ecc:   1.2,
+		Inc:   unit.AngleFromDeg(122.7),
+		ArgP:  unit.AngleFromDeg(241.8),
+		Node:  unit.AngleFromDeg(24.6),
+		TimeP: 2458006.0,
+		PDist: .256,
+	}
+	o := astro.NewOrbit(k)
+	last := 0.
+	for dt := 0.; dt < 3000; dt += 100 {
+		_, _, _, r := o.Position(k.TimeP + dt)
+		_, _, _, rm := o.Position(k.TimeP - dt)
+		if math.IsNaN(r) || r <= last {
+			t.Fatalf("dt %g: r = %f, previous %f", dt, r, last)
+		}
+		if math.Abs(r-rm) > 1e-9*r {
+			t.Fatalf("dt %g: r not symmetric about perihelion", dt)
+		}
+		last = r
+	}
+}
+
+func TestPositionVelocity(t *testing.T) {
+	for _, e := range []float64{0, .2, .95, 1, 1.5} {
+		k := &astro.Elements{
+			Axis:  2.2,
+			Ecc:   e,
+			Inc:   unit.AngleFromDeg(11.9),
+			ArgP:  unit.AngleFromDeg(186.2),
+			Node:  unit.AngleFromDeg(334.7),
+			TimeP: 2448193.0,
+			PDist: .34,
+		}
+		o := astro.NewOrbit(k)
+		for _, dt := range []float64{-100, -3, 0, 7, 40} {
+			jde := k.TimeP + dt
+			p, v := o.PositionVelocity(jde)
+			x, y, z, _ := o.Position(jde)
+			if p.X != x || p.Y != y || p.Z != z {
+				t.Fatalf("e %g dt %g: position mismatch", e, dt)
+			}
+			// compare to central difference
+			const h = 1e-3
+			x1, y1, z1, _ := o.Position(jde - h)
+			x2, y2, z2, _ := o.Position(jde + h)
+			d := math.Abs(v.X-(x2-x1)/(2*h)) +
+				math.Abs(v.Y-(y2-y1)/(2*h)) +
+				math.Abs(v.Z-(z2-z1)/(2*h))
+			if d > 1e-7 {
+				t.Errorf("e %g dt %g: velocity error %g", e, dt, d)
+			}
+		}
+	}
+}
+
+func ExampleOrbit_PositionVelocity() {
+	k := &astro.Elements{
+		Axis:  2.2091404,
+		Ecc:   .8502196,
+		Inc:   unit.AngleFromDeg(11.94524),
+		ArgP:  unit.AngleFromDeg(186.23352),
+		Node:  unit.AngleFromDeg(334.75006),
+		TimeP: 2448192.5 + .54502,
+	}
+	p, v := astro.NewOrbit(k).PositionVelocity(2448170.5)
+	// AeiHv wants velocity scaled by the gravitational constant
+	v.X *= astro.InvK
+	v.Y *= astro.InvK
+	v.Z *= astro.InvK
+	var hv coord.Cart
+	a, e, _, ok := astro.AeiHv(&p, &v, math.Sqrt(p.Square()), &hv)
+	fmt.Printf("a: %.7f AU\n", a)
+	fmt.Printf("e: %.7f\n", e)
+	fmt.Println("ok:", ok)
+	// Output:
+	// a: 2.2091404 AU
+	// e: 0.8502196
+	// ok: true
+}
+
+func TestElementsFromState(t *testing.T) {
+	// round trip elements through state vectors, including the
+	// circular and equatorial singularities.
+	for _, c := range []struct {
+		e, i float64
+	}{
+		{.3, 20}, {0, 20}, {.3, 0}, {0, 0}, {.3, 180}, {.99, 150},
+		{1, 60}, {1, 0}, {1.8, 100}, {1.8, 180},
+	} {
+		k := &astro.Elements{
+			Axis:  2.7,
+			Ecc:   c.e,
+			Inc:   unit.AngleFromDeg(c.i),
+			ArgP:  unit.AngleFromDeg(73),
+			Node:  unit.AngleFromDeg(201),
+			TimeP: 2451000.5,
+			PDist: 1.3,
+		}
+		o := astro.NewOrbit(k)
+		jde := 2451123.25
+		p, v := o.PositionVelocity(jde)
+		k2 := astro.ElementsFromState(&p, &v, jde)
+		if math.Abs(k2.Ecc-c.e) > 1e-9 ||
+			math.Abs(k2.Inc.Deg()-c.i) > 1e-7 {
+			t.Errorf("e, i = %g, %g, got %g, %g",
+				c.e, c.i, k2.Ecc, k2.Inc.Deg())
+		}
+		if c.e > 0 && c.i > 0 && c.i < 180 {
+			if math.Abs(k2.ArgP.Deg()-73) > 1e-6 ||
+				math.Abs(k2.Node.Deg()-201) > 1e-6 ||
+				math.Abs(k2.TimeP-k.TimeP) > 1e-6 {
+				t.Errorf("e, i = %g, %g: got ω %f Ω %f T %f", c.e, c.i,
+					k2.ArgP.Deg(), k2.Node.Deg(), k2.TimeP)
+			}
+		}
+		// singular cases don't give back the same angles, but must give
+		// back the same orbit.
+		o2 := astro.NewOrbit(k2)
+		for _, dt := range []float64{-300, 0, 50} {
+			p1, v1 := o.PositionVelocity(jde + dt)
+			p2, v2 := o2.PositionVelocity(jde + dt)
+			d := math.Abs(p1.X-p2.X) + math.Abs(p1.Y-p2.Y) +
+				math.Abs(p1.Z-p2.Z) + math.Abs(v1.X-v2.X) +
+				math.Abs(v1.Y-v2.Y) + math.Abs(v1.Z-v2.Z)
+			if d > 1e-8 {
+				t.Errorf("e, i = %g, %g dt %g: state differs by %g",
+					c.e, c.i, dt, d)
+			}
+		}
+	}
+}
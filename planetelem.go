@@ -0,0 +1,116 @@
+// Public domain
+
+package astro
+
+// Pe2000: approximate planet positions from mean Keplerian elements.
+
+import (
+	"errors"
+	"math"
+
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/unit"
+)
+
+// Mean elements and rates per Julian century, for the mean ecliptic and
+// equinox of J2000, valid 1800 AD to 2050 AD.  From E.M. Standish,
+// "Keplerian Elements for Approximate Positions of the Major Planets,"
+// JPL Solar System Dynamics, table 1.
+//
+// Elements are a, e, i, mean longitude L, longitude of perihelion ϖ, and
+// longitude of node Ω, with a in AU and angles in degrees.
+var approxElements = [...]struct{ el, rate [6]float64 }{
+	Mercury: {
+		[6]float64{0.38709927, 0.20563593, 7.00497902,
+			252.25032350, 77.45779628, 48.33076593},
+		[6]float64{0.00000037, 0.00001906, -0.00594749,
+			149472.67411175, 0.16047689, -0.12534081}},
+	Venus: {
+		[6]float64{0.72333566, 0.00677672, 3.39467605,
+			181.97909950, 131.60246718, 76.67984255},
+		[6]float64{0.00000390, -0.00004107, -0.00078890,
+			58517.81538729, 0.00268329, -0.27769418}},
+	Earth: { // Earth-Moon barycenter
+		[6]float64{1.00000261, 0.01671123, -0.00001531,
+			100.46457166, 102.93768193, 0.0},
+		[6]float64{0.00000562, -0.00004392, -0.01294668,
+			35999.37244981, 0.32327364, 0.0}},
+	Mars: {
+		[6]float64{1.52371034, 0.09339410, 1.84969142,
+			-4.55343205, -23.94362959, 49.55953891},
+		[6]float64{0.00001847, 0.00007882, -0.00813131,
+			19140.30268499, 0.44441088, -0.29257343}},
+	Jupiter: {
+		[6]float64{5.20288700, 0.04838624, 1.30439695,
+			34.39644051, 14.72847983, 100.47390909},
+		[6]float64{-0.00011607, -0.00013253, -0.00183714,
+			3034.74612775, 0.21252668, 0.20469106}},
+	Saturn: {
+		[6]float64{9.53667594, 0.05386179, 2.48599187,
+			49.95424423, 92.59887831, 113.66242448},
+		[6]float64{-0.00125060, -0.00050991, 0.00193609,
+			1222.49362201, -0.41897216, -0.28867794}},
+	Uranus: {
+		[6]float64{19.18916464, 0.04725744, 0.77263783,
+			313.23810451, 170.95427630, 74.01692503},
+		[6]float64{-0.00196176, -0.00004397, -0.00242939,
+			428.48202785, 0.40805281, 0.04240589}},
+	Neptune: {
+		[6]float64{30.06992276, 0.00859048, 1.77004347,
+			-55.12002969, 44.96476227, 131.78422574},
+		[6]float64{0.00026291, 0.00005105, 0.00035372,
+			218.45945325, -0.32241464, -0.00508664}},
+}
+
+// Pe2000 computes approximate planet ephemeris, J2000.
+//
+// Argument ibody must be one of the planet constants Mercury-Neptune or EMB.
+// Earth and EMB both give the Earth-Moon barycenter.  Argument mjd is
+// modified Julian day.
+//
+// Returns the sun-planet vector in equatorial coordinates, referenced to
+// the equinox of J2000, in AU.  See Se2000 for the Sun.  An error is
+// returned for an invalid ibody.
+//
+// Positions are from mean Keplerian elements with linear rates, per
+// Standish, "Keplerian Elements for Approximate Positions of the Major
+// Planets," https://ssd.jpl.nasa.gov/planets/approx_pos.html.  Errors over
+// 1800-2050 range from tens of arc seconds for the inner planets to ten arc
+// minutes for Saturn.
+func Pe2000(ibody int, mjd float64) (sunPlanet coord.Cart, err error) {
+	if ibody == EMB {
+		ibody = Earth
+	}
+	if ibody < Mercury || ibody > Neptune {
+		return sunPlanet, errors.New("Invalid planet.")
+	}
+	p := &approxElements[ibody]
+	T := (mjd - 51544.5) / JulianCentury
+	var el [6]float64
+	for i := range el {
+		el[i] = p.el[i] + p.rate[i]*T
+	}
+	a, e := el[0], el[1]
+	inc := unit.AngleFromDeg(el[2])
+	ϖ := unit.AngleFromDeg(el[4])
+	Ω := unit.AngleFromDeg(el[5])
+	M := unit.AngleFromDeg(el[3]) - ϖ
+	E, _, _ := kepler(e, M)
+	// coordinates in the orbital plane, x toward perihelion
+	sE, cE := E.Sincos()
+	x1 := a * (cE - e)
+	y1 := a * math.Sqrt(1-e*e) * sE
+	// rotate to the ecliptic
+	sω, cω := (ϖ - Ω).Sincos()
+	sΩ, cΩ := Ω.Sincos()
+	si, ci := inc.Sincos()
+	x := (cω*cΩ-sω*sΩ*ci)*x1 + (-sω*cΩ-cω*sΩ*ci)*y1
+	y := (cω*sΩ+sω*cΩ*ci)*x1 + (-sω*sΩ+cω*cΩ*ci)*y1
+	z := sω*si*x1 + cω*si*y1
+	// rotate to the equator, with the J2000 obliquity of Standish
+	sε, cε := unit.AngleFromDeg(23.43928).Sincos()
+	sunPlanet.X = x
+	sunPlanet.Y = cε*y - sε*z
+	sunPlanet.Z = sε*y + cε*z
+	return
+}
@@ -0,0 +1,51 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/coord"
+)
+
+func ExamplePe2000() {
+	// J2000.0
+	mjd := 51544.5
+	for _, ibody := range []int{astro.Mercury, astro.Earth, astro.Jupiter} {
+		p, err := astro.Pe2000(ibody, mjd)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("{X:%.3f Y:%.3f Z:%.3f}\n", p.X, p.Y, p.Z)
+	}
+	// Output:
+	// {X:-0.130 Y:-0.401 Z:-0.200}
+	// {X:-0.177 Y:0.887 Z:0.385}
+	// {X:3.998 Y:2.743 Z:1.078}
+}
+
+func TestPe2000(t *testing.T) {
+	// consistent with the approximate solar ephemeris near J2000,
+	// where Se2000 coordinates of date differ little from J2000.
+	for _, mjd := range []float64{51544.5, 51600, 51700.25} {
+		e, err := astro.Pe2000(astro.Earth, mjd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, _, _ := astro.Se2000(mjd)
+		var d coord.Cart
+		d.Add(&e, &s)
+		if m := math.Sqrt(d.Square()); m > .001 {
+			t.Errorf("mjd %.2f: Pe2000 Earth + Se2000 = %.5f AU", mjd, m)
+		}
+	}
+	for _, ibody := range []int{-1, astro.EMB + 1} {
+		if _, err := astro.Pe2000(ibody, 51544.5); err == nil {
+			t.Errorf("ibody %d: expected error", ibody)
+		}
+	}
+}
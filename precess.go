@@ -0,0 +1,286 @@
+// Public domain
+
+package astro
+
+// Precess: Chapter 21, Precession, and Chapter 24, Reduction of Ecliptical
+// Elements from one Equinox to another one.
+//
+// Functions here take epochs as Julian ephemeris days.  JulianYearToJDE and
+// BesselianYearToJDE convert from epochs given as years, such as J2050.0 or
+// B1950.0.
+
+import (
+	"math"
+
+	"github.com/soniakeys/unit"
+)
+
+// JulianYearToJDE returns the Julian ephemeris day for a Julian year.
+func JulianYearToJDE(jy float64) float64 {
+	return J2000 + JulianYear*(jy-2000)
+}
+
+// JDEToJulianYear returns the Julian year for a Julian ephemeris day.
+func JDEToJulianYear(jde float64) float64 {
+	return 2000 + (jde-J2000)/JulianYear
+}
+
+// BesselianYearToJDE returns the Julian ephemeris day for a Besselian year.
+func BesselianYearToJDE(by float64) float64 {
+	return B1900 + BesselianYear*(by-1900)
+}
+
+// JDEToBesselianYear returns the Besselian year for a Julian ephemeris day.
+func JDEToBesselianYear(jde float64) float64 {
+	return 1900 + (jde-B1900)/BesselianYear
+}
+
+// EquatorialPrecessor represents precession of equatorial coordinates
+// from one epoch to another.
+//
+// Construct with NewEquatorialPrecessor, then call method Precess as many
+// times as needed for the same initial and final epochs.
+type EquatorialPrecessor struct {
+	ζ, z   unit.Angle
+	sθ, cθ float64
+}
+
+// NewEquatorialPrecessor constructs an EquatorialPrecessor to precess
+// coordinates referenced to the mean equator and equinox of jdeFrom to
+// those of jdeTo.
+func NewEquatorialPrecessor(jdeFrom, jdeTo float64) *EquatorialPrecessor {
+	T := J2000Century(jdeFrom)
+	t := (jdeTo - jdeFrom) / JulianCentury
+	// (21.2) p. 134
+	c := Horner(T, 2306.2181, 1.39656, -0.000139)
+	ζ := unit.AngleFromSec(Horner(t, c, 0.30188-0.000344*T, 0.017998) * t)
+	z := unit.AngleFromSec(Horner(t, c, 1.09468+0.000066*T, 0.018203) * t)
+	θ := unit.AngleFromSec(Horner(t, Horner(T, 2004.3109, -0.8533, -0.000217),
+		-0.42665-0.000217*T, -0.041833) * t)
+	p := &EquatorialPrecessor{ζ: ζ, z: z}
+	p.sθ, p.cθ = θ.Sincos()
+	return p
+}
+
+// Precess precesses equatorial coordinates α, δ.
+func (p *EquatorialPrecessor) Precess(α unit.RA, δ unit.Angle) (unit.RA, unit.Angle) {
+	// (21.4) p. 134
+	sδ, cδ := δ.Sincos()
+	sαζ, cαζ := (α.Angle() + p.ζ).Sincos()
+	A := cδ * sαζ
+	B := p.cθ*cδ*cαζ - p.sθ*sδ
+	C := p.sθ*cδ*cαζ + p.cθ*sδ
+	return unit.RAFromRad(math.Atan2(A, B) + p.z.Rad()),
+		unit.Angle(math.Atan2(C, math.Hypot(A, B)))
+}
+
+// PrecessEquatorial precesses equatorial coordinates from the mean equator
+// and equinox of jdeFrom to those of jdeTo.
+//
+// It is a convenience function for NewEquatorialPrecessor and Precess.
+// Proper motion, if any, can be applied first with ProperMotion.
+func PrecessEquatorial(α unit.RA, δ unit.Angle, jdeFrom, jdeTo float64) (unit.RA, unit.Angle) {
+	return NewEquatorialPrecessor(jdeFrom, jdeTo).Precess(α, δ)
+}
+
+// ProperMotion applies annual proper motions mα, mδ to equatorial
+// coordinates α, δ over the time from jdeFrom to jdeTo.
+//
+// The motion is linear in α and δ, adequate for a few centuries away from
+// the poles.
+func ProperMotion(α unit.RA, δ unit.Angle, mα unit.HourAngle, mδ unit.Angle, jdeFrom, jdeTo float64) (unit.RA, unit.Angle) {
+	y := (jdeTo - jdeFrom) / JulianYear
+	return unit.RAFromRad(α.Rad() + mα.Rad()*y), δ + mδ.Mul(y)
+}
+
+// EclipticPrecessor represents precession of ecliptic coordinates from one
+// epoch to another.
+//
+// Construct with NewEclipticPrecessor, then call method Precess or
+// ReduceElements as many times as needed for the same initial and final
+// epochs.
+type EclipticPrecessor struct {
+	sη, cη float64
+	Π, p   unit.Angle
+}
+
+// NewEclipticPrecessor constructs an EclipticPrecessor to precess
+// coordinates referenced to the mean ecliptic and equinox of jdeFrom to
+// those of jdeTo.
+func NewEclipticPrecessor(jdeFrom, jdeTo float64) *EclipticPrecessor {
+	T := J2000Century(jdeFrom)
+	t := (jdeTo - jdeFrom) / JulianCentury
+	// (21.5) p. 136
+	η := unit.AngleFromSec(Horner(t, Horner(T, 47.0029, -0.06603, 0.000598),
+		-0.03302+0.000598*T, 0.000060) * t)
+	Π := unit.AngleFromDeg(174.876384) +
+		unit.AngleFromSec(Horner(T, 0, 3289.4789, 0.60622)) +
+		unit.AngleFromSec(Horner(t, -869.8089-0.50491*T, 0.03536)*t)
+	p := &EclipticPrecessor{
+		Π: Π,
+		p: unit.AngleFromSec(Horner(t, Horner(T, 5029.0966, 2.22226, -0.000042),
+			1.11113-0.000042*T, -0.000006) * t),
+	}
+	p.sη, p.cη = η.Sincos()
+	return p
+}
+
+// Precess precesses ecliptic coordinates λ, β.
+func (p *EclipticPrecessor) Precess(λ, β unit.Angle) (unit.Angle, unit.Angle) {
+	// (21.7) p. 137
+	sβ, cβ := β.Sincos()
+	sd, cd := (p.Π - λ).Sincos()
+	A := p.cη*cβ*sd - p.sη*sβ
+	B := cβ * cd
+	C := p.cη*sβ + p.sη*cβ*sd
+	return (p.p + p.Π - unit.Angle(math.Atan2(A, B))).Mod1(),
+		unit.Angle(math.Atan2(C, math.Hypot(A, B)))
+}
+
+// PrecessEcliptic precesses ecliptic coordinates from the mean ecliptic
+// and equinox of jdeFrom to those of jdeTo.
+//
+// It is a convenience function for NewEclipticPrecessor and Precess.
+func PrecessEcliptic(λ, β unit.Angle, jdeFrom, jdeTo float64) (unit.Angle, unit.Angle) {
+	return NewEclipticPrecessor(jdeFrom, jdeTo).Precess(λ, β)
+}
+
+// ReduceElements reduces the orbital elements Inc, ArgP, and Node of e
+// to the ecliptic and equinox of p.  Other elements are copied unchanged.
+func (p *EclipticPrecessor) ReduceElements(e *Elements) Elements {
+	r := *e
+	ψ := p.Π + p.p
+	si, ci := e.Inc.Sincos()
+	snp, cnp := (e.Node - p.Π).Sincos()
+	// (24.1) p. 159
+	r.Inc = unit.Angle(math.Acos(ci*p.cη + si*p.sη*cnp))
+	// (24.2) p. 159
+	r.Node = (ψ + unit.Angle(math.Atan2(si*snp, p.cη*si*cnp-p.sη*ci))).Mod1()
+	// (24.3) p. 160
+	r.ArgP = (e.ArgP +
+		unit.Angle(math.Atan2(-p.sη*snp, si*p.cη-ci*p.sη*cnp))).Mod1()
+	return r
+}
+
+// PrecessElements reduces orbital elements from the ecliptic and equinox
+// of jdeFrom to those of jdeTo.
+//
+// Elements of this package are referenced to J2000.  PrecessElements with
+// jdeTo = J2000 converts elements of other equinoxes for use with NewOrbit.
+// For elements of B1950 in the FK4 system, use ElementsFK4ToFK5.
+func PrecessElements(e *Elements, jdeFrom, jdeTo float64) Elements {
+	return NewEclipticPrecessor(jdeFrom, jdeTo).ReduceElements(e)
+}
+
+// ElementsFK4ToFK5 reduces orbital elements from the equinox B1950 in the
+// FK4 system to the equinox J2000 in the FK5 system.
+func ElementsFK4ToFK5(e *Elements) Elements {
+	// p. 161
+	L := unit.AngleFromDeg(5.19856209)
+	J := unit.AngleFromDeg(.00651966)
+	Lp := unit.AngleFromDeg(4.50001688)
+	r := *e
+	W := L + e.Node
+	si, ci := e.Inc.Sincos()
+	sJ, cJ := J.Sincos()
+	sW, cW := W.Sincos()
+	r.Inc = unit.Angle(math.Acos(ci*cJ - si*sJ*cW))
+	r.Node = (unit.Angle(math.Atan2(si*sW, ci*sJ+si*cJ*cW)) - Lp).Mod1()
+	r.ArgP = (e.ArgP +
+		unit.Angle(math.Atan2(sJ*sW, si*cJ+ci*sJ*cW))).Mod1()
+	return r
+}
+
+// FK4 to FK5 conversion by the method of Standish, A&A 115, 20 (1982),
+// as given in the Explanatory Supplement (1992) section 3.591.
+
+// E-terms of aberration, A, and their rate of change per tropical century,
+// Ȧ, in radians and arcseconds.
+var (
+	fk4A  = [3]float64{-1.62557e-6, -0.31919e-6, -0.13843e-6}
+	fk4Ad = [3]float64{1.245e-3, -1.580e-3, -0.659e-3}
+)
+
+// fk4M is the position part of the 6×6 matrix of Standish.  Rows 0-2 give
+// FK5 position, rows 3-5 give FK5 velocity in arcseconds per century.
+var fk4M = [6][3]float64{
+	{+0.9999256782, -0.0111820611, -0.0048579477},
+	{+0.0111820610, +0.9999374784, -0.0000271765},
+	{+0.0048579479, -0.0000271474, +0.9999881997},
+	{-0.000551, -0.238565, +0.435739},
+	{+0.238514, -0.002667, -0.008541},
+	{-0.435623, +0.012254, +0.002117},
+}
+
+// secPerRad is arcseconds per radian.
+const secPerRad = 180 * 3600 / math.Pi
+
+// FK4ToFK5 converts mean equatorial coordinates from B1950 in the FK4
+// system to J2000 in the FK5 system.
+//
+// Argument jde is the epoch of observation of the FK4 position.  The star
+// is assumed to have no proper motion in the FK5 system, as is appropriate
+// for catalog positions lacking proper motions; pass B1950 if the epoch
+// is unknown.  E-terms of aberration are removed in the conversion.
+func FK4ToFK5(α unit.RA, δ unit.Angle, jde float64) (unit.RA, unit.Angle) {
+	r := fk4Vec(α, δ)
+	v := fk4Convert(r, jde)
+	return unit.RAFromRad(math.Atan2(v[1], v[0])),
+		unit.Angle(math.Atan2(v[2], math.Hypot(v[0], v[1])))
+}
+
+// FK5ToFK4 converts mean equatorial coordinates from J2000 in the FK5
+// system to B1950 in the FK4 system, the inverse of FK4ToFK5.
+//
+// Argument jde is the epoch of observation for the FK4 position.  The star
+// is assumed to have no proper motion in the FK5 system.  E-terms of
+// aberration are included in the result.
+func FK5ToFK4(α unit.RA, δ unit.Angle, jde float64) (unit.RA, unit.Angle) {
+	t := fk4Vec(α, δ)
+	// the conversion is a rotation plus small terms.  iterate, correcting
+	// by the inverse of the rotation.
+	r := t
+	for i := 0; i < 4; i++ {
+		v := fk4Convert(r, jde)
+		var d [3]float64
+		for j := range d {
+			d[j] = t[j] - v[j]
+		}
+		for j := range r {
+			r[j] += fk4M[0][j]*d[0] + fk4M[1][j]*d[1] + fk4M[2][j]*d[2]
+		}
+	}
+	return unit.RAFromRad(math.Atan2(r[1], r[0])),
+		unit.Angle(math.Atan2(r[2], math.Hypot(r[0], r[1])))
+}
+
+// fk4Vec returns the unit vector of α, δ.
+func fk4Vec(α unit.RA, δ unit.Angle) [3]float64 {
+	sα, cα := α.Sincos()
+	sδ, cδ := δ.Sincos()
+	return [3]float64{cδ * cα, cδ * sα, sδ}
+}
+
+// fk4Convert converts FK4 vector r to an FK5 vector of nearly unit length.
+func fk4Convert(r [3]float64, jde float64) (v [3]float64) {
+	// adjust A to give zero proper motion in FK5
+	w := (JDEToBesselianYear(jde) - 1950) / 100 / secPerRad
+	var a [3]float64
+	for i := range a {
+		a[i] = fk4A[i] + w*fk4Ad[i]
+	}
+	// remove E-terms
+	w = r[0]*a[0] + r[1]*a[1] + r[2]*a[2]
+	var r1 [3]float64
+	for i := range r1 {
+		r1[i] = r[i] - a[i] + w*r[i]
+	}
+	// rotate, allowing for fictitious proper motion in FK4
+	w = (JDEToJulianYear(jde) - 2000) / 100 / secPerRad
+	for i := range v {
+		m, md := fk4M[i], fk4M[i+3]
+		v[i] = m[0]*r1[0] + m[1]*r1[1] + m[2]*r1[2] +
+			w*(md[0]*r1[0]+md[1]*r1[1]+md[2]*r1[2])
+	}
+	return
+}
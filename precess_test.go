@@ -0,0 +1,140 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/sexagesimal"
+	"github.com/soniakeys/unit"
+)
+
+func ExamplePrecessEquatorial() {
+	// Example 21.b, p. 135.
+	α := unit.NewRA(2, 44, 11.986)
+	δ := unit.NewAngle(' ', 49, 13, 42.48)
+	jdeTo := 2462088.69 // 2028 November 13.19 TD
+	α, δ = astro.ProperMotion(α, δ, unit.HourAngleFromSec(0.03425),
+		unit.AngleFromSec(-0.0895), astro.J2000, jdeTo)
+	α, δ = astro.PrecessEquatorial(α, δ, astro.J2000, jdeTo)
+	fmt.Printf("%0.3d\n", sexa.FmtRA(α))
+	fmt.Printf("%+0.2d\n", sexa.FmtAngle(δ))
+	// Output:
+	// 2ʰ46ᵐ11ˢ.331
+	// +49°20′54″.54
+}
+
+func ExamplePrecessEcliptic() {
+	// Example 21.c, p. 137.
+	λ, β := astro.PrecessEcliptic(unit.AngleFromDeg(149.48194),
+		unit.AngleFromDeg(1.76549), astro.J2000, 1643074.5) // -214 June 30
+	fmt.Printf("%.3f\n", λ.Deg())
+	fmt.Printf("%+.3f\n", β.Deg())
+	// Output:
+	// 118.704
+	// +1.615
+}
+
+func ExamplePrecessElements() {
+	// Example 24.a, p. 160.
+	e := &astro.Elements{
+		Inc:  unit.AngleFromDeg(47.122),
+		ArgP: unit.AngleFromDeg(151.4486),
+		Node: unit.AngleFromDeg(45.7481),
+	}
+	r := astro.PrecessElements(e,
+		astro.BesselianYearToJDE(1744), astro.BesselianYearToJDE(1950))
+	fmt.Printf("i = %.4f\n", r.Inc.Deg())
+	fmt.Printf("Ω = %.4f\n", r.Node.Deg())
+	fmt.Printf("ω = %.4f\n", r.ArgP.Deg())
+	// Output:
+	// i = 47.1380
+	// Ω = 48.6037
+	// ω = 151.4782
+}
+
+func ExampleElementsFK4ToFK5() {
+	// Example 24.c, p. 162.
+	e := &astro.Elements{
+		Inc:  unit.AngleFromDeg(11.93911),
+		Node: unit.AngleFromDeg(334.04096),
+		ArgP: unit.AngleFromDeg(186.24444),
+	}
+	r := astro.ElementsFK4ToFK5(e)
+	fmt.Printf("i = %.5f\n", r.Inc.Deg())
+	fmt.Printf("Ω = %.5f\n", r.Node.Deg())
+	fmt.Printf("ω = %.5f\n", r.ArgP.Deg())
+	// Output:
+	// i = 11.94521
+	// Ω = 334.75043
+	// ω = 186.23327
+}
+
+func TestEpoch(t *testing.T) {
+	// p. 133
+	if math.Abs(astro.BesselianYearToJDE(1950)-astro.B1950) > 1e-4 {
+		t.Error("B1950")
+	}
+	if astro.JulianYearToJDE(2050) != 2469807.5 {
+		t.Error("J2050")
+	}
+	if y := astro.JDEToBesselianYear(astro.B1900); y != 1900 {
+		t.Error("B1900", y)
+	}
+	if y := astro.JDEToJulianYear(astro.J2000); y != 2000 {
+		t.Error("J2000", y)
+	}
+}
+
+// Exercise, p. 136.
+func TestPrecessEquatorial(t *testing.T) {
+	α0 := unit.NewRA(2, 31, 48.704)
+	δ0 := unit.NewAngle(' ', 89, 15, 50.72)
+	mα := unit.HourAngleFromSec(0.19877)
+	mδ := unit.AngleFromSec(-0.0152)
+	for _, tc := range []struct {
+		α, δ string
+		jde  float64
+	}{
+		{"1ʰ22ᵐ33.90ˢ", "88°46′26.18″", astro.BesselianYearToJDE(1900)},
+		{"3ʰ48ᵐ16.43ˢ", "89°27′15.38″", astro.JulianYearToJDE(2050)},
+		{"5ʰ53ᵐ29.17ˢ", "89°32′22.18″", astro.JulianYearToJDE(2100)},
+	} {
+		α, δ := astro.ProperMotion(α0, δ0, mα, mδ, astro.J2000, tc.jde)
+		α, δ = astro.PrecessEquatorial(α, δ, astro.J2000, tc.jde)
+		if s := fmt.Sprintf("%.2s", sexa.FmtRA(α)); s != tc.α {
+			t.Error("got", s, "expected", tc.α)
+		}
+		if s := fmt.Sprintf("%.2s", sexa.FmtAngle(δ)); s != tc.δ {
+			t.Error("got", s, "expected", tc.δ)
+		}
+	}
+	// round trip
+	p := astro.NewEquatorialPrecessor(astro.B1950, astro.J2000)
+	q := astro.NewEquatorialPrecessor(astro.J2000, astro.B1950)
+	α, δ := q.Precess(p.Precess(α0, δ0))
+	if d := math.Abs(α.Rad()-α0.Rad()) + math.Abs((δ - δ0).Rad()); d > 1e-12 {
+		t.Error("round trip", d)
+	}
+}
+
+func TestFK4ToFK5(t *testing.T) {
+	// test case of SLALIB sla_FK45Z
+	α, δ := astro.FK4ToFK5(unit.RAFromRad(1.2), -.3,
+		astro.BesselianYearToJDE(1960))
+	if math.Abs(α.Rad()-1.2097812228966762227) > 1e-12 ||
+		math.Abs(δ.Rad()+0.29826111711331398935) > 1e-12 {
+		t.Errorf("FK4ToFK5 = %.15f, %.15f", α.Rad(), δ.Rad())
+	}
+	// round trip
+	for _, jde := range []float64{astro.B1950, astro.BesselianYearToJDE(1875)} {
+		α1, δ1 := astro.FK5ToFK4(α, δ, jde)
+		α2, δ2 := astro.FK4ToFK5(α1, δ1, jde)
+		if d := math.Abs(α2.Rad()-α.Rad()) + math.Abs((δ2 - δ).Rad()); d > 1e-14 {
+			t.Error("round trip", d)
+		}
+	}
+}
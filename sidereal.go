@@ -0,0 +1,124 @@
+// Public domain
+
+package astro
+
+// Sidereal: Chapter 12, Sidereal Time at Greenwich, with the IAU 2000/2006
+// Earth rotation angle based expressions.
+
+import (
+	"math"
+
+	"github.com/soniakeys/unit"
+)
+
+// ERA returns the Earth rotation angle of the IAU 2000 resolutions.
+//
+// Argument ut1 is Julian date in UT1.
+func ERA(ut1 float64) unit.Angle {
+	// fractional day taken separately to preserve precision
+	_, f := math.Modf(ut1)
+	d := ut1 - J2000
+	return unit.Angle(2 * math.Pi *
+		(f + .7790572732640 + .00273781191135448*d)).Mod1()
+}
+
+// GMST82 returns Greenwich mean sidereal time by the IAU 1982 expression.
+//
+// Argument ut1 is Julian date in UT1.  Result is in the range [0, 1 day).
+func GMST82(ut1 float64) unit.Time {
+	t := J2000Century(ut1)
+	// (12.4) p. 88, with the UT1 day fraction taken separately.
+	_, f := math.Modf(ut1 - .5)
+	s := Horner(t, 24110.54841, 8640184.812866, 0.093104, -6.2e-6)
+	return (unit.Time(s) + unit.TimeFromDay(f)).Mod1()
+}
+
+// GMST06 returns Greenwich mean sidereal time by the IAU 2006 expression
+// based on the Earth rotation angle.
+//
+// Argument ut1 is Julian date in UT1, tt is the same instant as Julian
+// ephemeris day (TT).  Result is in the range [0, 1 day).
+func GMST06(ut1, tt float64) unit.Time {
+	p := unit.AngleFromSec(Horner(J2000Century(tt), 0.014506,
+		4612.156534, 1.3915817, -0.00000044, -0.000029956, -0.0000000368))
+	return (ERA(ut1) + p).Mod1().Time()
+}
+
+// EqEquinoxes82 returns the equation of the equinoxes, apparent minus mean
+// sidereal time, by the IAU 1994 expression for use with GMST82.
+//
+// Argument tt is Julian ephemeris day.
+func EqEquinoxes82(tt float64) unit.Time {
+	Δψ, _ := Nutation(tt)
+	T := J2000Century(tt)
+	Ω := unit.AngleFromDeg(Horner(T, 125.04452, -1934.136261, 0.0020708))
+	ε0 := MeanObliquity(tt)
+	return (Δψ.Mul(ε0.Cos()) +
+		unit.AngleFromSec(.00264*Ω.Sin()+.000063*(2*Ω).Sin())).Time()
+}
+
+// EqEquinoxes06 returns the equation of the equinoxes, apparent minus mean
+// sidereal time, consistent with GMST06.
+//
+// Argument tt is Julian ephemeris day.  Nutation is by the IAU 2000B
+// model.
+func EqEquinoxes06(tt float64) unit.Time {
+	Δψ, _ := Nutation2000B(tt)
+	T := J2000Century(tt)
+	l, lp, F, D, Ω := delaunay(T)
+	// complementary terms, IERS Conventions 2003 table 5.2e
+	var c float64
+	for i := len(tableEECT) - 1; i >= 0; i-- {
+		row := &tableEECT[i]
+		s, co := (row.l*l + row.lp*lp + row.f*F + row.d*D + row.ω*Ω).Sincos()
+		c += row.s*s + row.c*co
+	}
+	c -= .87 * T * Ω.Sin()
+	return (Δψ.Mul(MeanObliquity2006(tt).Cos()) +
+		unit.AngleFromSec(c*1e-6)).Time()
+}
+
+// Complementary terms of the equation of the equinoxes.  Multipliers of
+// l, l′, F, D, Ω, then sine and cosine coefficients in µas.  Terms smaller
+// than 1 µas are neglected.
+var tableEECT = []struct {
+	l, lp, f, d, ω unit.Angle
+	s, c           float64
+}{
+	{0, 0, 0, 0, 1, 2640.96, -.39},
+	{0, 0, 0, 0, 2, 63.52, -.02},
+	{0, 0, 2, -2, 3, 11.75, .01},
+	{0, 0, 2, -2, 1, 11.21, .01},
+	{0, 0, 2, -2, 2, -4.55, 0},
+	{0, 0, 2, 0, 3, 2.02, 0},
+	{0, 0, 2, 0, 1, 1.98, 0},
+	{0, 0, 0, 0, 3, -1.72, 0},
+	{0, 1, 0, 0, 1, -1.41, -.01},
+	{0, 1, 0, 0, -1, -1.26, -.01},
+}
+
+// GAST82 returns Greenwich apparent sidereal time, GMST82 plus
+// EqEquinoxes82.
+//
+// Argument ut1 is Julian date in UT1, tt is the same instant as Julian
+// ephemeris day.  Result is in the range [0, 1 day).
+func GAST82(ut1, tt float64) unit.Time {
+	return (GMST82(ut1) + EqEquinoxes82(tt)).Mod1()
+}
+
+// GAST06 returns Greenwich apparent sidereal time, GMST06 plus
+// EqEquinoxes06.
+//
+// Argument ut1 is Julian date in UT1, tt is the same instant as Julian
+// ephemeris day.  Result is in the range [0, 1 day).
+func GAST06(ut1, tt float64) unit.Time {
+	return (GMST06(ut1, tt) + EqEquinoxes06(tt)).Mod1()
+}
+
+// LocalSidereal returns local sidereal time from Greenwich sidereal time.
+//
+// Argument gst is Greenwich mean or apparent sidereal time, as from GMST06
+// or GAST06.  Argument long is observer longitude, positive east as for Lst.
+func LocalSidereal(gst unit.Time, long unit.Angle) unit.Time {
+	return (gst + long.Time()).Mod1()
+}
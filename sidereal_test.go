@@ -0,0 +1,61 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/sexagesimal"
+	"github.com/soniakeys/unit"
+)
+
+func ExampleGAST82() {
+	// Example 12.a, p. 88.  Meeus gives apparent time 13ʰ10ᵐ46ˢ.1351,
+	// without the IAU 1994 terms of the equation of the equinoxes.
+	jd := 2446895.5
+	fmt.Printf("%.4s\n", sexa.FmtTime(astro.GMST82(jd)))
+	fmt.Printf("%.4s\n", sexa.FmtTime(astro.GAST82(jd, jd)))
+	// Output:
+	// 13ʰ10ᵐ46.3668ˢ
+	// 13ʰ10ᵐ46.1352ˢ
+}
+
+func ExampleLocalSidereal() {
+	ut1 := 2460000.5
+	tt := ut1 + 69.2/86400
+	gst := astro.GAST06(ut1, tt)
+	// Kitt Peak, 111°36′ W
+	fmt.Printf("%.3s\n", sexa.FmtTime(astro.LocalSidereal(gst,
+		unit.NewAngle('-', 111, 36, 0))))
+	// Output:
+	// 2ʰ51ᵐ59.896ˢ
+}
+
+// SOFA test values, from t_sofa_c.c.
+func TestSidereal(t *testing.T) {
+	jd := 2400000.5 + 53736
+	for _, c := range []struct {
+		name      string
+		got, want float64
+		tol       float64
+	}{
+		{"ERA", astro.ERA(2400000.5 + 54388).Rad(), 0.4022837240028158102, 1e-12},
+		{"GMST82", astro.GMST82(jd).Rad(), 1.754174981860675096, 1e-12},
+		{"GMST06", astro.GMST06(jd, jd).Rad(), 1.754174971870091203, 1e-12},
+		// 1980 and 2000B nutation here differ slightly from the full
+		// series used by SOFA.
+		{"GAST82", astro.GAST82(jd, jd).Rad(), 1.754166136020645203, 5e-9},
+		{"GAST06", astro.GAST06(jd, jd).Rad(), 1.754166137675019159, 5e-9},
+		{"EqEquinoxes82", astro.EqEquinoxes82(2400000.5 + 41234).Rad(),
+			0.5357758254609256894e-4, 5e-9},
+		{"EqEquinoxes06", astro.EqEquinoxes06(jd).Rad(),
+			-0.8834195072043790156e-5, 5e-9},
+	} {
+		if math.Abs(c.got-c.want) > c.tol {
+			t.Errorf("%s = %.15f, want %.15f", c.name, c.got, c.want)
+		}
+	}
+}
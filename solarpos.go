@@ -7,17 +7,26 @@ package astro
 import (
 	"math"
 
+	"github.com/soniakeys/coord"
 	"github.com/soniakeys/unit"
 )
 
 // PositionJ2000 returns rectangular coordinates referenced to equinox J2000.
 func SolarPositionJ2000(e *V87Planet, jde float64) (x, y, z, r float64) {
 	x, y, z, r = xyzr(e, jde)
+	p := vsopToFK5(coord.Cart{X: x, Y: y, Z: z})
+	return p.X, p.Y, p.Z, r
+}
+
+// vsopToFK5 rotates rectangular coordinates from the VSOP87 ecliptic
+// J2000 frame to the equatorial FK5 J2000 frame.
+func vsopToFK5(p coord.Cart) coord.Cart {
 	// (26.3) p. 174
-	return x + .00000044036*y - .000000190919*z,
-		-.000000479966*x + .917482137087*y - .397776982902*z,
-		.397776982902*y + .917482137087*z,
-		r
+	return coord.Cart{
+		X: p.X + .00000044036*p.Y - .000000190919*p.Z,
+		Y: -.000000479966*p.X + .917482137087*p.Y - .397776982902*p.Z,
+		Z: .397776982902*p.Y + .917482137087*p.Z,
+	}
 }
 
 func xyzr(e *V87Planet, jde float64) (x, y, z, r float64) {
@@ -33,3 +42,39 @@ func xyzr(e *V87Planet, jde float64) (x, y, z, r float64) {
 	z = r * sβ
 	return
 }
+
+// Chapter 25, Solar Coordinates, low accuracy.
+
+// solarTrue returns true geometric longitude and true anomaly of the Sun
+// referenced to the mean equinox of date.
+//
+// Argument T is Julian centuries since J2000.
+func solarTrue(T float64) (s, ν unit.Angle) {
+	// (25.2) p. 163
+	L0 := unit.AngleFromDeg(Horner(T, 280.46646, 36000.76983, 0.0003032))
+	// (25.3) p. 163
+	M := unit.AngleFromDeg(Horner(T, 357.52911, 35999.05029, -0.0001537))
+	C := unit.AngleFromDeg(Horner(T, 1.914602, -0.004817, -.000014)*M.Sin() +
+		(0.019993-.000101*T)*(2*M).Sin() + 0.000289*(3*M).Sin())
+	return (L0 + C).Mod1(), (M + C).Mod1()
+}
+
+// earthEccentricity returns eccentricity of the Earth's orbit.
+func earthEccentricity(T float64) float64 {
+	// (25.4) p. 163
+	return Horner(T, 0.016708634, -0.000042037, -0.0000001267)
+}
+
+// solarApparent returns apparent longitude of the Sun, referenced to the
+// true equinox of date, and the Sun-Earth distance in AU.
+func solarApparent(jde float64) (λ unit.Angle, R float64) {
+	T := J2000Century(jde)
+	s, ν := solarTrue(T)
+	e := earthEccentricity(T)
+	// (25.5) p. 164
+	R = 1.000001018 * (1 - e*e) / (1 + e*ν.Cos())
+	// p. 164
+	Ω := unit.AngleFromDeg(125.04 - 1934.136*T)
+	λ = s - unit.AngleFromDeg(.00569+.00478*Ω.Sin())
+	return
+}
@@ -0,0 +1,333 @@
+// Public domain
+
+package astro
+
+// SPK: JPL development ephemerides in SPICE SPK (DAF) form.
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/soniakeys/coord"
+)
+
+// NAIF integer codes of some bodies in JPL development ephemerides.
+const (
+	NAIFSSB     = 0   // solar system barycenter
+	NAIFEMB     = 3   // Earth-Moon barycenter
+	NAIFSun     = 10  // Sun
+	NAIFMoon    = 301 // Moon
+	NAIFEarth   = 399 // Earth
+	NAIFMercury = 199 // Mercury
+	NAIFVenus   = 299 // Venus
+)
+
+// NAIFID returns the NAIF integer code for a body constant.
+//
+// Mars through Neptune are represented in JPL development ephemerides by
+// their system barycenters, codes 4-8, which are returned for those
+// bodies.
+func NAIFID(ibody int) int {
+	switch ibody {
+	case Mercury:
+		return NAIFMercury
+	case Venus:
+		return NAIFVenus
+	case Earth:
+		return NAIFEarth
+	case EMB:
+		return NAIFEMB
+	case Sun:
+		return NAIFSun
+	}
+	return ibody + 1 // planet barycenters
+}
+
+// SPK reads position and velocity from a JPL SPK file such as DE430 or
+// DE440.
+//
+// Segments of types 2 and 3, Chebyshev polynomials, in the J2000 frame are
+// supported; other segments are skipped.  Data is read as needed from the underlying file.  Methods may be called
+// concurrently.
+type SPK struct {
+	r    io.ReaderAt
+	c    io.Closer
+	bo   binary.ByteOrder
+	segs []spkSegment
+}
+
+type spkSegment struct {
+	begin, end     float64 // TDB seconds from J2000
+	target, center int
+	frame, typ     int
+	start          int64 // byte offset of segment data
+	// segment directory
+	init, intLen float64
+	rsize, n     int
+}
+
+const spkRecord = 1024
+
+// spkJ2000 is the SPICE frame code of the J2000 frame, equatorial.
+const spkJ2000 = 1
+
+// OpenSPK opens the named SPK file.  Close the SPK to close the file.
+func OpenSPK(name string) (*SPK, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	s, err := NewSPK(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	s.c = f
+	return s, nil
+}
+
+// NewSPK constructs an SPK reading from r.
+func NewSPK(r io.ReaderAt) (*SPK, error) {
+	fr := make([]byte, spkRecord)
+	if _, err := r.ReadAt(fr, 0); err != nil {
+		return nil, err
+	}
+	if string(fr[:8]) != "DAF/SPK " {
+		return nil, errors.New("Not an SPK file.")
+	}
+	s := &SPK{r: r}
+	switch string(fr[88:96]) {
+	case "LTL-IEEE":
+		s.bo = binary.LittleEndian
+	case "BIG-IEEE":
+		s.bo = binary.BigEndian
+	default:
+		return nil, fmt.Errorf("Unsupported SPK format %q.",
+			strings.TrimSpace(string(fr[88:96])))
+	}
+	nd := int(int32(s.bo.Uint32(fr[8:])))
+	ni := int(int32(s.bo.Uint32(fr[12:])))
+	if nd != 2 || ni != 6 {
+		return nil, fmt.Errorf("SPK: unexpected ND, NI = %d, %d.", nd, ni)
+	}
+	ss := nd + (ni+1)/2 // summary size in doubles
+	next := int(int32(s.bo.Uint32(fr[76:])))
+	rec := make([]byte, spkRecord)
+	for next > 0 {
+		if _, err := r.ReadAt(rec, int64(next-1)*spkRecord); err != nil {
+			return nil, err
+		}
+		d := func(i int) float64 {
+			return math.Float64frombits(s.bo.Uint64(rec[i*8:]))
+		}
+		next = int(d(0))
+		nsum := int(d(2))
+		if 3+nsum*ss > spkRecord/8 {
+			return nil, errors.New("SPK: invalid summary record.")
+		}
+		for i := 0; i < nsum; i++ {
+			o := (3 + i*ss) * 8
+			in := func(j int) int {
+				return int(int32(s.bo.Uint32(rec[o+16+j*4:])))
+			}
+			seg := spkSegment{
+				begin:  d(3 + i*ss),
+				end:    d(3 + i*ss + 1),
+				target: in(0),
+				center: in(1),
+				frame:  in(2),
+				typ:    in(3),
+				start:  int64(in(4)-1) * 8,
+			}
+			if seg.frame != spkJ2000 || seg.typ != 2 && seg.typ != 3 {
+				continue
+			}
+			var dir [32]byte
+			if _, err := r.ReadAt(dir[:], int64(in(5)-4)*8); err != nil {
+				return nil, err
+			}
+			seg.init = math.Float64frombits(s.bo.Uint64(dir[0:]))
+			seg.intLen = math.Float64frombits(s.bo.Uint64(dir[8:]))
+			seg.rsize = int(math.Float64frombits(s.bo.Uint64(dir[16:])))
+			seg.n = int(math.Float64frombits(s.bo.Uint64(dir[24:])))
+			if seg.rsize < 2 || seg.n < 1 || seg.intLen <= 0 {
+				return nil, errors.New("SPK: invalid segment directory.")
+			}
+			s.segs = append(s.segs, seg)
+		}
+	}
+	return s, nil
+}
+
+// Close closes the file opened by OpenSPK.  It does nothing for an SPK
+// constructed by NewSPK.
+func (s *SPK) Close() error {
+	if s.c == nil {
+		return nil
+	}
+	return s.c.Close()
+}
+
+// spkDay is the number of seconds in a day.
+const spkDay = 86400
+
+// State returns position and velocity of target relative to center.
+//
+// Target and center are NAIF integer codes.  Argument jde is Julian
+// ephemeris day (TDB).  States are chained through intermediate centers
+// as needed, for example Earth from the Sun goes through the Earth-Moon
+// barycenter and the solar system barycenter.
+//
+// Results are rectangular equatorial coordinates in the ICRF, which for
+// JPL development ephemerides is the J2000 frame.  Position p is in AU,
+// velocity v is in AU/day.
+func (s *SPK) State(target, center int, jde float64) (p, v coord.Cart, err error) {
+	return s.state(target, center, (jde-J2000)*spkDay)
+}
+
+// state returns State for et in TDB seconds from J2000.
+func (s *SPK) state(target, center int, et float64) (p, v coord.Cart, err error) {
+	tc, err := s.chain(target, et)
+	if err != nil {
+		return
+	}
+	cc, err := s.chain(center, et)
+	if err != nil {
+		return
+	}
+	for _, t := range tc {
+		for _, c := range cc {
+			if t.body == c.body {
+				const au = AU * 1e-3 // km
+				p.Sub(&t.p, &c.p)
+				p.MulScalar(&p, 1/au)
+				v.Sub(&t.v, &c.v)
+				v.MulScalar(&v, spkDay/au)
+				return
+			}
+		}
+	}
+	err = fmt.Errorf("SPK: no data relating body %d to %d at %.1f.",
+		target, center, J2000+et/spkDay)
+	return
+}
+
+// Barycentric returns position and velocity of body relative to the solar
+// system barycenter.  See State.
+func (s *SPK) Barycentric(body int, jde float64) (p, v coord.Cart, err error) {
+	return s.State(body, NAIFSSB, jde)
+}
+
+// Heliocentric returns position and velocity of body relative to the Sun.
+// See State.
+func (s *SPK) Heliocentric(body int, jde float64) (p, v coord.Cart, err error) {
+	return s.State(body, NAIFSun, jde)
+}
+
+// Position2000 returns heliocentric equatorial J2000 position of body in
+// AU, in the manner of V87Planet.Position2000.  See State.
+func (s *SPK) Position2000(body int, jde float64) (p coord.Cart, err error) {
+	p, _, err = s.Heliocentric(body, jde)
+	return
+}
+
+// spkNode is the state, in km and km/s, of a body relative to another
+// body of its chain of centers.
+type spkNode struct {
+	body int
+	p, v coord.Cart
+}
+
+// chain returns states of body relative to itself and successive centers
+// of segments covering et, in TDB seconds from J2000.
+func (s *SPK) chain(body int, et float64) ([]spkNode, error) {
+	n := []spkNode{{body: body}}
+	for body != NAIFSSB && len(n) < 10 {
+		seg := s.segment(body, et)
+		if seg == nil {
+			break
+		}
+		sp, sv, err := s.eval(seg, et)
+		if err != nil {
+			return nil, err
+		}
+		last := n[len(n)-1]
+		last.body = seg.center
+		last.p.Add(&last.p, &sp)
+		last.v.Add(&last.v, &sv)
+		n = append(n, last)
+		body = seg.center
+	}
+	return n, nil
+}
+
+// segment returns the last segment for target covering et.  Later
+// segments in a file take precedence.
+func (s *SPK) segment(target int, et float64) *spkSegment {
+	for i := len(s.segs) - 1; i >= 0; i-- {
+		seg := &s.segs[i]
+		if seg.target == target && et >= seg.begin && et <= seg.end {
+			return seg
+		}
+	}
+	return nil
+}
+
+// eval evaluates the Chebyshev record of seg covering et.
+func (s *SPK) eval(seg *spkSegment, et float64) (p, v coord.Cart, err error) {
+	i := int((et - seg.init) / seg.intLen)
+	if i < 0 {
+		i = 0
+	} else if i >= seg.n {
+		i = seg.n - 1
+	}
+	buf := make([]byte, seg.rsize*8)
+	_, err = s.r.ReadAt(buf, seg.start+int64(i*seg.rsize)*8)
+	if err != nil {
+		return
+	}
+	rec := make([]float64, seg.rsize)
+	for j := range rec {
+		rec[j] = math.Float64frombits(s.bo.Uint64(buf[j*8:]))
+	}
+	mid, radius := rec[0], rec[1]
+	ncomp := 3
+	if seg.typ == 3 {
+		ncomp = 6
+	}
+	nc := (seg.rsize - 2) / ncomp
+	x := (et - mid) / radius
+	// Chebyshev polynomials and derivatives
+	t := make([]float64, nc)
+	dt := make([]float64, nc)
+	t[0] = 1
+	if nc > 1 {
+		t[1] = x
+		dt[1] = 1
+	}
+	for k := 2; k < nc; k++ {
+		t[k] = 2*x*t[k-1] - t[k-2]
+		dt[k] = 2*t[k-1] + 2*x*dt[k-1] - dt[k-2]
+	}
+	// sum series in reverse order
+	sum := func(j int, f []float64) (y float64) {
+		cf := rec[2+j*nc : 2+(j+1)*nc]
+		for k := nc - 1; k >= 0; k-- {
+			y += cf[k] * f[k]
+		}
+		return
+	}
+	p = coord.Cart{X: sum(0, t), Y: sum(1, t), Z: sum(2, t)}
+	if seg.typ == 3 {
+		v = coord.Cart{X: sum(3, t), Y: sum(4, t), Z: sum(5, t)}
+	} else {
+		v = coord.Cart{X: sum(0, dt), Y: sum(1, dt), Z: sum(2, dt)}
+		v.MulScalar(&v, 1/radius)
+	}
+	return
+}
@@ -0,0 +1,128 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/coord"
+)
+
+// Polynomial motions of testdata/test.bsp, written by testdata/genspk.go.
+// Positions are in km, d is days from J2000.
+var spkTestBodies = map[int]struct {
+	center int
+	f      func(d float64) [3]float64
+	df     func(d float64) [3]float64 // km/day
+}{
+	astro.NAIFEMB: {astro.NAIFSSB,
+		func(d float64) [3]float64 {
+			return [3]float64{1e8 + 2.5e6*d + 1000*d*d,
+				-5e7 + 1.2e6*d - 300*d*d + 2*d*d*d, 1e6 * d}
+		},
+		func(d float64) [3]float64 {
+			return [3]float64{2.5e6 + 2000*d, 1.2e6 - 600*d + 6*d*d, 1e6}
+		}},
+	astro.NAIFSun: {astro.NAIFSSB,
+		func(d float64) [3]float64 {
+			return [3]float64{1e5 + 10*d, 2e5, -3e4 * d}
+		},
+		func(d float64) [3]float64 { return [3]float64{10, 0, -3e4} }},
+	astro.NAIFEarth: {astro.NAIFEMB,
+		func(d float64) [3]float64 {
+			return [3]float64{4000 + 100*d, -2000 + 50*d*d, 10 * d * d * d}
+		},
+		func(d float64) [3]float64 {
+			return [3]float64{100, 100 * d, 30 * d * d}
+		}},
+	astro.NAIFMoon: {astro.NAIFEMB,
+		func(d float64) [3]float64 {
+			return [3]float64{-3.8e5 + 1e4*d, 2e3 * d * d, 0}
+		},
+		func(d float64) [3]float64 { return [3]float64{1e4, 4e3 * d, 0} }},
+}
+
+// spkTestSSB returns the barycentric state of body in AU and AU/day.
+func spkTestSSB(body int, d float64) (p, v coord.Cart) {
+	const au = astro.AU * 1e-3
+	for body != astro.NAIFSSB {
+		b := spkTestBodies[body]
+		f, df := b.f(d), b.df(d)
+		p.X += f[0] / au
+		p.Y += f[1] / au
+		p.Z += f[2] / au
+		v.X += df[0] / au
+		v.Y += df[1] / au
+		v.Z += df[2] / au
+		body = b.center
+	}
+	return
+}
+
+func ExampleSPK_Heliocentric() {
+	s, err := astro.OpenSPK("testdata/test.bsp")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer s.Close()
+	p, v, err := s.Heliocentric(astro.NAIFEarth, astro.J2000+2.5)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("p: {X:%.6f Y:%.6f Z:%.6f}\n", p.X, p.Y, p.Z)
+	fmt.Printf("v: {X:%.6f Y:%.6f Z:%.6f}\n", v.X, v.Y, v.Z)
+	// Output:
+	// p: {X:0.709639 Y:-0.315536 Z:0.017214}
+	// v: {X:0.016745 Y:0.008013 Z:0.006886}
+}
+
+func TestSPK(t *testing.T) {
+	s, err := astro.OpenSPK("testdata/test.bsp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	bodies := []int{astro.NAIFSSB, astro.NAIFEMB, astro.NAIFSun,
+		astro.NAIFEarth, astro.NAIFMoon}
+	for _, d := range []float64{-10, -7.3, 0, 2.5, 5, 9.99, 10} {
+		jde := astro.J2000 + d
+		d = jde - astro.J2000 // as represented in jde
+		for _, target := range bodies {
+			for _, center := range bodies {
+				p, v, err := s.State(target, center, jde)
+				if err != nil {
+					t.Fatal(d, target, center, err)
+				}
+				pt, vt := spkTestSSB(target, d)
+				pc, vc := spkTestSSB(center, d)
+				pt.Sub(&pt, &pc)
+				vt.Sub(&vt, &vc)
+				pt.Sub(&pt, &p)
+				vt.Sub(&vt, &v)
+				if math.Sqrt(pt.Square()) > 1e-14 {
+					t.Errorf("d %g, %d from %d: position off by %g AU",
+						d, target, center, math.Sqrt(pt.Square()))
+				}
+				if math.Sqrt(vt.Square()) > 1e-14 {
+					t.Errorf("d %g, %d from %d: velocity off by %g AU/day",
+						d, target, center, math.Sqrt(vt.Square()))
+				}
+			}
+		}
+	}
+	if _, _, err := s.Barycentric(astro.NAIFVenus, astro.J2000); err == nil {
+		t.Error("Venus: expected error")
+	}
+	if _, _, err := s.Barycentric(astro.NAIFEarth, astro.J2000+11); err == nil {
+		t.Error("out of range: expected error")
+	}
+	// the fixture has a Mars barycenter segment only in ECLIPJ2000
+	if _, _, err := s.Barycentric(4, astro.J2000); err == nil {
+		t.Error("non-J2000 frame: expected error")
+	}
+}
@@ -0,0 +1,163 @@
+// Public domain
+
+//go:build ignore
+// +build ignore
+
+// Genspk writes test.bsp, a small SPK file used by the SPK tests.
+//
+// Segments hold polynomial motions, which Chebyshev series represent
+// exactly, so that tests can compare against closed forms.  The same
+// polynomials are in spk_test.go.  Run from the testdata directory:
+//
+//	go run genspk.go
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"log"
+	"math"
+	"os"
+)
+
+const day = 86400
+
+type segment struct {
+	target, center int
+	frame, typ     int
+	name           string
+	f              func(d float64) [3]float64 // position, km
+	df             func(d float64) [3]float64 // velocity, km/day
+}
+
+// polynomials of d, days from J2000.  segments in frame 17, ECLIPJ2000,
+// are not J2000 and must be skipped by the reader.  They are last so that
+// they would otherwise take precedence.
+var segs = []segment{
+	{3, 0, 1, 2, "EMB",
+		func(d float64) [3]float64 {
+			return [3]float64{1e8 + 2.5e6*d + 1000*d*d,
+				-5e7 + 1.2e6*d - 300*d*d + 2*d*d*d, 1e6 * d}
+		}, nil},
+	{10, 0, 1, 2, "SUN",
+		func(d float64) [3]float64 {
+			return [3]float64{1e5 + 10*d, 2e5, -3e4 * d}
+		}, nil},
+	{399, 3, 1, 3, "EARTH",
+		func(d float64) [3]float64 {
+			return [3]float64{4000 + 100*d, -2000 + 50*d*d, 10 * d * d * d}
+		},
+		func(d float64) [3]float64 {
+			return [3]float64{100, 100 * d, 30 * d * d}
+		}},
+	{301, 3, 1, 2, "MOON",
+		func(d float64) [3]float64 {
+			return [3]float64{-3.8e5 + 1e4*d, 2e3 * d * d, 0}
+		}, nil},
+	{301, 3, 17, 2, "MOON ECLIPJ2000",
+		func(d float64) [3]float64 {
+			return [3]float64{3.8e5, -1e4 * d, 5e3}
+		}, nil},
+	{4, 0, 17, 2, "MARS BARYCENTER ECLIPJ2000",
+		func(d float64) [3]float64 {
+			return [3]float64{2e8, 1e6 * d, 0}
+		}, nil},
+}
+
+const (
+	begin  = -10 * day // segment coverage, TDB seconds from J2000
+	end    = 10 * day
+	intLen = 5 * day
+	nc     = 4 // coefficients per component
+)
+
+// cheb returns Chebyshev coefficients of g on [-1, 1], exact for
+// polynomials of degree < nc.
+func cheb(g func(s float64) float64) []float64 {
+	c := make([]float64, nc)
+	for k := range c {
+		for j := 0; j < nc; j++ {
+			θ := math.Pi * (float64(j) + .5) / nc
+			c[k] += g(math.Cos(θ)) * math.Cos(float64(k)*θ)
+		}
+		c[k] *= 2. / nc
+	}
+	c[0] /= 2
+	return c
+}
+
+func main() {
+	le := binary.LittleEndian
+	var data []float64
+	var sums [][5]float64
+	const dataStart = 3*128 + 1 // word address of record 4
+	for _, sg := range segs {
+		start := dataStart + len(data)
+		n := (end - begin) / intLen
+		ncomp := 3
+		if sg.typ == 3 {
+			ncomp = 6
+		}
+		for i := 0; i < n; i++ {
+			mid := begin + (float64(i)+.5)*intLen
+			rad := intLen / 2.
+			data = append(data, mid, rad)
+			for j := 0; j < ncomp; j++ {
+				j := j
+				data = append(data, cheb(func(s float64) float64 {
+					d := (mid + rad*s) / day
+					if j < 3 {
+						return sg.f(d)[j]
+					}
+					return sg.df(d)[j-3] / day
+				})...)
+			}
+		}
+		data = append(data, begin, intLen, float64(2+ncomp*nc), float64(n))
+		var sum [5]float64
+		sum[0], sum[1] = begin, end
+		var ints [24]byte
+		for i, v := range []int{sg.target, sg.center, sg.frame, sg.typ,
+			start, dataStart + len(data) - 1} {
+			le.PutUint32(ints[i*4:], uint32(int32(v)))
+		}
+		for i := 0; i < 3; i++ {
+			sum[2+i] = math.Float64frombits(le.Uint64(ints[i*8:]))
+		}
+		sums = append(sums, sum)
+	}
+	var b bytes.Buffer
+	// file record
+	fr := make([]byte, 1024)
+	copy(fr, "DAF/SPK ")
+	le.PutUint32(fr[8:], 2)  // ND
+	le.PutUint32(fr[12:], 6) // NI
+	copy(fr[16:76], "astro SPK test fixture"+string(bytes.Repeat([]byte{' '}, 60)))
+	le.PutUint32(fr[76:], 2)                                        // FWARD
+	le.PutUint32(fr[80:], 2)                                        // BWARD
+	le.PutUint32(fr[84:], uint32(dataStart+len(data)))              // FREE
+	copy(fr[88:], "LTL-IEEE")                                       // LOCFMT
+	copy(fr[699:], "FTPSTR:\r:\n:\r\n:\r\x00:\x81:\x10\xce:ENDFTP") // FTPSTR
+	b.Write(fr)
+	// summary record
+	rec := make([]float64, 128)
+	rec[2] = float64(len(sums))
+	for i, s := range sums {
+		copy(rec[3+i*5:], s[:])
+	}
+	binary.Write(&b, le, rec)
+	// name record
+	nr := bytes.Repeat([]byte{' '}, 1024)
+	for i, sg := range segs {
+		copy(nr[i*40:], sg.name)
+	}
+	b.Write(nr)
+	binary.Write(&b, le, data)
+	// pad to a whole record
+	if r := b.Len() % 1024; r != 0 {
+		b.Write(make([]byte, 1024-r))
+	}
+	if err := os.WriteFile("test.bsp", b.Bytes(), 0666); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,283 @@
+// Public domain
+
+package astro
+
+// Timescale: conversions between UTC, TAI, TT, UT1, and TDB.
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/soniakeys/unit"
+)
+
+// Scale identifies a time scale.
+type Scale int
+
+// Time scales.
+const (
+	UTC Scale = iota // Coordinated Universal Time
+	TAI              // International Atomic Time
+	TT               // Terrestrial Time, the jde of Meeus
+	UT1              // Universal Time
+	TDB              // Barycentric Dynamical Time
+)
+
+// String returns the abbreviation of the time scale.
+func (s Scale) String() string {
+	switch s {
+	case UTC:
+		return "UTC"
+	case TAI:
+		return "TAI"
+	case TT:
+		return "TT"
+	case UT1:
+		return "UT1"
+	case TDB:
+		return "TDB"
+	}
+	return "Scale(?)"
+}
+
+// Epoch is a Julian date with the time scale it is reckoned in.
+//
+// Functions of this package taking jde expect TT, or equivalently for most
+// purposes, TDB.  Epoch.To converts between scales so that, for example,
+// a UTC date is not mistakenly used as a jde.
+type Epoch struct {
+	JD    float64
+	Scale Scale
+}
+
+// EpochFromTime returns the UTC Epoch of a Go time.Time.
+//
+// Any time zone offset in the time.Time is accounted for.  The resolution
+// of the result is that of a float64 Julian date, about 40 µs.
+func EpochFromTime(t time.Time) Epoch {
+	return Epoch{TimeToJD(t), UTC}
+}
+
+// To returns the Epoch converted to time scale s.
+//
+// Conversions are through TT.  UTC before 1972 is not modeled and is taken
+// to be UT1.  UT1 is computed from TT with ΔT by DeltaTEspenakMeeus; DUT1,
+// the difference UT1 − UTC, is thus implied by the ΔT model and the leap
+// second table.  Use ToDeltaT for a different model.
+func (e Epoch) To(s Scale) Epoch {
+	return e.ToDeltaT(s, DeltaTEspenakMeeus)
+}
+
+// ToDeltaT returns the Epoch converted to time scale s as To, but with ΔT
+// given by the function ΔT, for example the DeltaT method of a DeltaTTable
+// of observed values.
+//
+// Argument ΔT is called with a Julian date that may be in either TT or UT;
+// the difference is negligible.
+func (e Epoch) ToDeltaT(s Scale, ΔT func(jd float64) unit.Time) Epoch {
+	if e.Scale == s {
+		return e
+	}
+	var tt float64
+	switch e.Scale {
+	case UTC:
+		tt = e.JD + utcToTT(e.JD, ΔT).Day()
+	case TAI:
+		tt = e.JD + ttMinusTAI.Day()
+	case TT:
+		tt = e.JD
+	case UT1:
+		tt = e.JD + ΔT(e.JD).Day()
+	case TDB:
+		tt = e.JD - TDBMinusTT(e.JD).Day()
+	}
+	r := Epoch{tt, s}
+	switch s {
+	case UTC:
+		// leap seconds are reckoned in UTC, so correct twice
+		u := tt - utcToTT(tt, ΔT).Day()
+		r.JD = tt - utcToTT(u, ΔT).Day()
+	case TAI:
+		r.JD = tt - ttMinusTAI.Day()
+	case UT1:
+		// ΔT is evaluated at UT1 as for the inverse conversion
+		r.JD = tt - ΔT(tt-ΔT(tt).Day()).Day()
+	case TDB:
+		r.JD = tt + TDBMinusTT(tt).Day()
+	}
+	return r
+}
+
+// TT returns the Julian date of e in the TT scale, suitable as a jde
+// argument.
+func (e Epoch) TT() float64 {
+	return e.To(TT).JD
+}
+
+// ttMinusTAI is the fixed offset TT − TAI.
+const ttMinusTAI unit.Time = 32.184
+
+// utcToTT returns TT − UTC for Julian date utc, with ΔT before 1972.
+func utcToTT(utc float64, ΔT func(jd float64) unit.Time) unit.Time {
+	if utc-JMod < leapSeconds[0].mjd {
+		return ΔT(utc)
+	}
+	return TAIMinusUTC(utc) + ttMinusTAI
+}
+
+// Leap seconds.  MJD of the UTC day from which TAI − UTC takes the value
+// Δ seconds.  Source: IERS Bulletin C.
+var leapSeconds = []struct{ mjd, Δ float64 }{
+	{41317, 10}, // 1972 Jan 1
+	{41499, 11}, // 1972 Jul 1
+	{41683, 12}, // 1973 Jan 1
+	{42048, 13}, // 1974 Jan 1
+	{42413, 14}, // 1975 Jan 1
+	{42778, 15}, // 1976 Jan 1
+	{43144, 16}, // 1977 Jan 1
+	{43509, 17}, // 1978 Jan 1
+	{43874, 18}, // 1979 Jan 1
+	{44239, 19}, // 1980 Jan 1
+	{44786, 20}, // 1981 Jul 1
+	{45151, 21}, // 1982 Jul 1
+	{45516, 22}, // 1983 Jul 1
+	{46247, 23}, // 1985 Jul 1
+	{47161, 24}, // 1988 Jan 1
+	{47892, 25}, // 1990 Jan 1
+	{48257, 26}, // 1991 Jan 1
+	{48804, 27}, // 1992 Jul 1
+	{49169, 28}, // 1993 Jul 1
+	{49534, 29}, // 1994 Jul 1
+	{50083, 30}, // 1996 Jan 1
+	{50630, 31}, // 1997 Jul 1
+	{51179, 32}, // 1999 Jan 1
+	{53736, 33}, // 2006 Jan 1
+	{54832, 34}, // 2009 Jan 1
+	{56109, 35}, // 2012 Jul 1
+	{57204, 36}, // 2015 Jul 1
+	{57754, 37}, // 2017 Jan 1
+}
+
+// TAIMinusUTC returns TAI − UTC for Julian date utc, from the table of
+// leap seconds.
+//
+// The result is 0 before 1972.  After the last table entry the last value
+// is returned.
+func TAIMinusUTC(utc float64) unit.Time {
+	mjd := utc - JMod
+	i := sort.Search(len(leapSeconds), func(i int) bool {
+		return leapSeconds[i].mjd > mjd
+	})
+	if i == 0 {
+		return 0
+	}
+	return unit.Time(leapSeconds[i-1].Δ)
+}
+
+// DeltaTEspenakMeeus returns ΔT = TT − UT1 for Julian date jd by the
+// polynomial expressions of Espenak and Meeus, Five Millennium Canon of Solar
+// Eclipses (2006).
+//
+// Values after 2005 are extrapolations.
+func DeltaTEspenakMeeus(jd float64) unit.Time {
+	y := 2000 + (jd-J2000)/JulianYear
+	var ΔT float64
+	switch {
+	case y < -500:
+		u := (y - 1820) * .01
+		ΔT = -20 + 32*u*u
+	case y < 500:
+		ΔT = Horner(y*.01, 10583.6, -1014.41, 33.78311, -5.952053,
+			-0.1798452, 0.022174192, 0.0090316521)
+	case y < 1600:
+		ΔT = Horner((y-1000)*.01, 1574.2, -556.01, 71.23472, 0.319781,
+			-0.8503463, -0.005050998, 0.0083572073)
+	case y < 1700:
+		ΔT = Horner(y-1600, 120, -0.9808, -0.01532, 1./7129)
+	case y < 1800:
+		ΔT = Horner(y-1700, 8.83, 0.1603, -0.0059285, 0.00013336,
+			-1./1174000)
+	case y < 1860:
+		ΔT = Horner(y-1800, 13.72, -0.332447, 0.0068612, 0.0041116,
+			-0.00037436, 0.0000121272, -0.0000001699, 0.000000000875)
+	case y < 1900:
+		ΔT = Horner(y-1860, 7.62, 0.5737, -0.251754, 0.01680668,
+			-0.0004473624, 1./233174)
+	case y < 1920:
+		ΔT = Horner(y-1900, -2.79, 1.494119, -0.0598939, 0.0061966,
+			-0.000197)
+	case y < 1941:
+		ΔT = Horner(y-1920, 21.20, 0.84493, -0.076100, 0.0020936)
+	case y < 1961:
+		ΔT = Horner(y-1950, 29.07, 0.407, -1./233, 1./2547)
+	case y < 1986:
+		ΔT = Horner(y-1975, 45.45, 1.067, -1./260, -1./718)
+	case y < 2005:
+		ΔT = Horner(y-2000, 63.86, 0.3345, -0.060374, 0.0017275,
+			0.000651814, 0.00002373599)
+	case y < 2050:
+		ΔT = Horner(y-2000, 62.92, 0.32217, 0.005589)
+	case y < 2150:
+		u := (y - 1820) * .01
+		ΔT = -20 + 32*u*u - 0.5628*(2150-y)
+	default:
+		u := (y - 1820) * .01
+		ΔT = -20 + 32*u*u
+	}
+	return unit.Time(ΔT)
+}
+
+// DeltaTTable holds tabulated values of ΔT, such as observed values
+// published by the IERS.
+//
+// JD must be in ascending order, with ΔT the corresponding values.  To use
+// the table for time scale conversions, pass its DeltaT method to
+// Epoch.ToDeltaT.
+type DeltaTTable struct {
+	JD []float64
+	ΔT []unit.Time
+	// Model gives ΔT outside the range of the table.  If nil,
+	// DeltaTEspenakMeeus is used.
+	Model func(jd float64) unit.Time
+}
+
+// DeltaT returns ΔT at jd by linear interpolation in the table.
+func (t *DeltaTTable) DeltaT(jd float64) unit.Time {
+	n := len(t.JD)
+	if n == 0 || jd < t.JD[0] || jd > t.JD[n-1] {
+		if t.Model == nil {
+			return DeltaTEspenakMeeus(jd)
+		}
+		return t.Model(jd)
+	}
+	i := sort.SearchFloat64s(t.JD, jd)
+	if t.JD[i] == jd {
+		return t.ΔT[i]
+	}
+	f := (jd - t.JD[i-1]) / (t.JD[i] - t.JD[i-1])
+	return t.ΔT[i-1] + (t.ΔT[i] - t.ΔT[i-1]).Mul(f)
+}
+
+// TDBMinusTT returns TDB − TT for Julian date tt.
+//
+// The periodic terms are those of Fairhead and Bretagnon as abbreviated in
+// USNO Circular 179 (2005), (2.6).  Accuracy is 10 µs over 1600-2200.
+func TDBMinusTT(tt float64) unit.Time {
+	T := J2000Century(tt)
+	return unit.Time(0.001657*math.Sin(628.3076*T+6.2401) +
+		0.000022*math.Sin(575.3385*T+4.2970) +
+		0.000014*math.Sin(1256.6152*T+6.1969) +
+		0.000005*math.Sin(606.9777*T+4.0212) +
+		0.000005*math.Sin(52.9691*T+0.4444) +
+		0.000002*math.Sin(21.3299*T+5.5431) +
+		0.000010*T*math.Sin(628.3076*T+4.2490))
+}
+
+// PositionEpoch returns the position of the body as Position2000, at the
+// date e of any time scale.
+//
+// VSOP87 is a theory in TDB; e is converted to TDB.
+func (vt *V87Planet) PositionEpoch(e Epoch) (L, B unit.Angle, R float64) {
+	return vt.Position2000(e.To(TDB).JD)
+}
@@ -0,0 +1,107 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/unit"
+)
+
+func ExampleEpoch_To() {
+	utc := astro.EpochFromTime(time.Date(2017, 1, 1, 0, 0, 0, 0, time.UTC))
+	for _, s := range []astro.Scale{astro.TAI, astro.TT, astro.TDB} {
+		e := utc.To(s)
+		fmt.Printf("%-3v − UTC = %.3f s\n", s, (e.JD-utc.JD)*86400)
+	}
+	// Output:
+	// TAI − UTC = 37.000 s
+	// TT  − UTC = 69.184 s
+	// TDB − UTC = 69.184 s
+}
+
+func ExampleDeltaTEspenakMeeus() {
+	// Example 10.a, p. 78.
+	jd := astro.MeeusCalendarGregorianToJD(1977, 2, 18)
+	fmt.Printf("%.0f s\n", astro.DeltaTEspenakMeeus(jd).Sec())
+	// Output:
+	// 48 s
+}
+
+func TestLeapSeconds(t *testing.T) {
+	for _, tc := range []struct {
+		y, m int
+		Δ    float64
+	}{
+		{1972, 1, 10}, {1981, 7, 20}, {1999, 1, 32}, {2017, 1, 37},
+	} {
+		jd := astro.CalendarGregorianToMJD(tc.y, tc.m, 1) + astro.JMod
+		if Δ := astro.TAIMinusUTC(jd).Sec(); Δ != tc.Δ {
+			t.Errorf("%d-%02d: TAI − UTC = %g, expected %g", tc.y, tc.m, Δ, tc.Δ)
+		}
+		if Δ := astro.TAIMinusUTC(jd - 1e-6).Sec(); Δ != tc.Δ-1 && tc.Δ > 10 {
+			t.Errorf("%d-%02d: TAI − UTC before = %g", tc.y, tc.m, Δ)
+		}
+	}
+	if Δ := astro.TAIMinusUTC(2400000.5); Δ != 0 {
+		t.Error("TAI − UTC before 1972 =", Δ)
+	}
+}
+
+func TestEpochRoundTrip(t *testing.T) {
+	scales := []astro.Scale{astro.UTC, astro.TAI, astro.TT, astro.UT1, astro.TDB}
+	for _, jd := range []float64{2305447.5, 2451545, 2457754.5, 2469807.5} {
+		for _, s1 := range scales {
+			e := astro.Epoch{JD: jd, Scale: s1}
+			for _, s2 := range scales {
+				r := e.To(s2).To(s1)
+				if d := math.Abs(r.JD - jd); d > 1e-9 {
+					t.Errorf("%.1f %v → %v → %v: error %g day", jd, s1, s2, s1, d)
+				}
+			}
+		}
+	}
+	// UT1 from UTC after 1972 should be within a second or so
+	e := astro.Epoch{JD: 2451545, Scale: astro.UTC}
+	if d := (e.To(astro.UT1).JD - e.JD) * 86400; math.Abs(d) > 1 {
+		t.Error("UT1 − UTC =", d)
+	}
+}
+
+func TestTDBMinusTT(t *testing.T) {
+	for jd := 2451545.; jd < 2451545+366; jd += 5 {
+		if d := astro.TDBMinusTT(jd).Sec(); math.Abs(d) > .0017 {
+			t.Fatalf("TDB − TT = %g at %.1f", d, jd)
+		}
+	}
+}
+
+func TestDeltaTTable(t *testing.T) {
+	tab := &astro.DeltaTTable{
+		JD: []float64{2451544.5, 2451910.5},
+		ΔT: []unit.Time{63.8285, 64.0908},
+	}
+	if d := tab.DeltaT(2451544.5); d != 63.8285 {
+		t.Error(d)
+	}
+	if d := tab.DeltaT((2451544.5 + 2451910.5) / 2).Sec(); math.Abs(d-63.95965) > 1e-9 {
+		t.Error(d)
+	}
+	if tab.DeltaT(2415020) != astro.DeltaTEspenakMeeus(2415020) {
+		t.Error("model outside table")
+	}
+	e := astro.Epoch{JD: 2451544.5, Scale: astro.UT1}
+	if d := (e.ToDeltaT(astro.TT, tab.DeltaT).JD - e.JD) * 86400; math.Abs(d-63.8285) > 1e-4 {
+		t.Error("TT − UT1 =", d)
+	}
+	// UTC before 1972 is taken as UT1, by the same model
+	u := astro.Epoch{JD: 2436934.5, Scale: astro.UTC}
+	c := func(float64) unit.Time { return 30 }
+	if d := (u.ToDeltaT(astro.TT, c).JD - u.JD) * 86400; math.Abs(d-30) > 1e-4 {
+		t.Error("1960 TT − UTC =", d)
+	}
+}
@@ -3,18 +3,28 @@
 package astro
 
 import (
+	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"math"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 
+	"github.com/soniakeys/coord"
 	"github.com/soniakeys/unit"
 )
 
-// Mercury-Neptune planet constants suitable for first argument to LoadPlanet.
+// Body constants suitable for first argument to LoadPlanet.
+//
+// Mercury-Neptune are the planets.  EMB, the Earth-Moon barycenter, is
+// available in VSOP87 and VSOP87A series only; Sun is available in VSOP87E
+// series only.
 const (
 	Mercury = iota
 	Venus
@@ -24,17 +34,19 @@ const (
 	Saturn
 	Uranus
 	Neptune
-	nPlanets // sad practicality
+	EMB
+	Sun
+	nBodies // sad practicality
 )
 
-// parallel arrays, indexed by planet constants.
+// parallel arrays, indexed by body constants.
 var (
-	// extensions of VSOP87B files
-	ext = [nPlanets]string{
-		"mer", "ven", "ear", "mar", "jup", "sat", "ura", "nep"}
+	// extensions of VSOP87 files
+	ext = [nBodies]string{
+		"mer", "ven", "ear", "mar", "jup", "sat", "ura", "nep", "emb", "sun"}
 
-	// planet names as found in VSOP87B files
-	b7 = [nPlanets]string{
+	// body names as found in VSOP87 files
+	b7 = [nBodies]string{
 		"MERCURY",
 		"VENUS  ",
 		"EARTH  ",
@@ -43,70 +55,321 @@ var (
 		"SATURN ",
 		"URANUS ",
 		"NEPTUNE",
+		"EMB    ",
+		"SUN    ",
 	}
 )
 
+// V87Version identifies a version of VSOP87 and so the kind of coordinates
+// and the reference frame of a series.
+//
+// Version codes are those found in VSOP87 files.
+type V87Version int
+
+// VSOP87 versions.
+const (
+	V87Elliptic V87Version = iota // heliocentric elliptic elements, J2000
+	V87A                          // heliocentric rectangular, J2000
+	V87B                          // heliocentric spherical, J2000
+	V87C                          // heliocentric rectangular, of date
+	V87D                          // heliocentric spherical, of date
+	V87E                          // barycentric rectangular, J2000
+	nVersions
+)
+
+// String returns the version name as used in VSOP87 file names.
+func (v V87Version) String() string {
+	if v < 0 || v >= nVersions {
+		return fmt.Sprintf("V87Version(%d)", int(v))
+	}
+	if v == V87Elliptic {
+		return "VSOP87"
+	}
+	return "VSOP87" + string("ABCDE"[v-1])
+}
+
+// FileName returns the name of the VSOP87 file of version v for body
+// ibody, for example VSOP87A.emb.
+//
+// Argument ibody should be one of the body constants.
+func (v V87Version) FileName(ibody int) string {
+	if ibody < 0 || ibody >= nBodies {
+		return ""
+	}
+	return v.String() + "." + ext[ibody]
+}
+
+// Rectangular reports whether series of version v are of rectangular
+// coordinates X, Y, Z.
+func (v V87Version) Rectangular() bool {
+	return v == V87A || v == V87C || v == V87E
+}
+
+// Spherical reports whether series of version v are of spherical
+// coordinates L, B, R.
+func (v V87Version) Spherical() bool {
+	return v == V87B || v == V87D
+}
+
+// Barycentric reports whether series of version v are referred to the
+// barycenter of the solar system rather than the Sun.
+func (v V87Version) Barycentric() bool {
+	return v == V87E
+}
+
+// OfDate reports whether series of version v are referred to the mean
+// ecliptic and equinox of date rather than the ecliptic and equinox J2000.
+func (v V87Version) OfDate() bool {
+	return v == V87C || v == V87D
+}
+
+// nVar returns the number of variables in a series of version v.
+func (v V87Version) nVar() int {
+	if v == V87Elliptic {
+		return 6
+	}
+	return 3
+}
+
+// hasBody reports whether VSOP87 provides series for ibody in version v.
+func (v V87Version) hasBody(ibody int) bool {
+	switch ibody {
+	case Earth:
+		return v != V87Elliptic
+	case EMB:
+		return v == V87Elliptic || v == V87A
+	case Sun:
+		return v == V87E
+	}
+	return ibody >= 0 && ibody < nBodies
+}
+
 type abc struct {
 	a, b, c float64
 }
 
 type coeff [6][]abc
 
-// V87Planet holds VSOP87 coefficients for computing planetary
-// positions in spherical coorditates.
+// V87Planet holds VSOP87 coefficients for computing positions of a body.
+//
+// The kind of coordinates and reference frame are those of the version
+// of VSOP87 loaded, reported by the Version method.
 type V87Planet struct {
-	l, b, r coeff
+	version V87Version
+	body    int
+	series  [6]coeff // indexed by variable, only nVar used.
+	// root sum square of amplitudes of terms dropped by Truncate,
+	// indexed by variable and power of time.
+	dropped [6][6]float64
+}
+
+// Version returns the VSOP87 version of the series held in vt.
+func (vt *V87Planet) Version() V87Version {
+	return vt.version
 }
 
-// code tested with version 2.  other versions unknown.
-const fileVersion = '2'
+// Body returns the body constant of the series held in vt.
+func (vt *V87Planet) Body() int {
+	return vt.body
+}
+
+// Terms returns the total number of terms in the series held in vt.
+func (vt *V87Planet) Terms() (n int) {
+	for _, c := range vt.series {
+		for _, terms := range c {
+			n += len(terms)
+		}
+	}
+	return
+}
 
-// LoadPlanet constructs a V87Planet object from a VSOP87 file.
+// Truncate returns a copy of vt with small terms dropped.
+//
+// Terms are dropped where the amplitude is less than prec, in units of the
+// series variable, radians or AU for example.  The threshold applies at each
+// power of τ, so that over the nominal span of |τ| <= 1 Julian millennium
+// of VSOP87 each dropped term contributes less than prec.
+//
+// The original vt is not modified.  Terms are shared between vt and the
+// result.  See TruncationError for the estimated effect of dropped terms.
+func (vt *V87Planet) Truncate(prec float64) *V87Planet {
+	t := &V87Planet{version: vt.version, body: vt.body, dropped: vt.dropped}
+	for x := range vt.series {
+		for α, terms := range vt.series[x] {
+			ss := t.dropped[x][α] * t.dropped[x][α]
+			var kept []abc
+			for _, term := range terms {
+				if math.Abs(term.a) < prec {
+					ss += term.a * term.a
+				} else {
+					kept = append(kept, term)
+				}
+			}
+			t.series[x][α] = kept
+			t.dropped[x][α] = math.Sqrt(ss)
+		}
+	}
+	return t
+}
+
+// TruncationError returns estimated errors due to terms dropped by
+// Truncate.
+//
+// Argument jde is the date for which positions are to be computed.
+// The result has an element for each variable of the series, in file
+// order: L, B, R for spherical versions, X, Y, Z for rectangular versions,
+// and a, λ, k, h, q, p for V87Elliptic.  The estimate for each variable is
+// the sum over powers α of τ^α times the root sum square of amplitudes
+// dropped at that power.  Results are zero for series not truncated.
+func (vt *V87Planet) TruncationError(jde float64) []float64 {
+	τ := math.Abs(J2000Century(jde) * .1)
+	e := make([]float64, vt.version.nVar())
+	for x := range e {
+		e[x] = Horner(τ, vt.dropped[x][:]...)
+	}
+	return e
+}
+
+// LoadPlanet constructs a V87Planet object from a VSOP87B file.
 //
 // Argument ibody should be one of the planet constants.
 //
 // The directory containing the VSOP87 must be indicated by environment
 // variable VSOP87.
 func LoadPlanet(ibody int) (*V87Planet, error) {
+	return LoadPlanetVersion(ibody, V87B)
+}
+
+// LoadPlanetVersion constructs a V87Planet object from a VSOP87 file of
+// the specified version.
+//
+// Argument ibody should be one of the body constants.
+//
+// The directory containing the VSOP87 must be indicated by environment
+// variable VSOP87.
+func LoadPlanetVersion(ibody int, v V87Version) (*V87Planet, error) {
 	path := os.Getenv("VSOP87")
 	if path == "" {
 		return nil, errors.New("No path assigned to environment variable VSOP87")
 	}
-	return LoadPlanetPath(ibody, path)
+	return LoadPlanetPathVersion(ibody, v, path)
 }
 
-// LoadPlanetPath constructs a V87Planet object from a VSOP87 file.
+// LoadPlanetPath constructs a V87Planet object from a VSOP87B file.
 //
 // Argument ibody should be one of the planet constants; path should be
 // a directory containing the VSOP87 files.
 func LoadPlanetPath(ibody int, path string) (*V87Planet, error) {
-	if ibody < 0 || ibody >= nPlanets {
+	return LoadPlanetPathVersion(ibody, V87B, path)
+}
+
+// LoadPlanetPathVersion constructs a V87Planet object from a VSOP87 file
+// of the specified version.
+//
+// Argument ibody should be one of the body constants; path should be
+// a directory containing the VSOP87 files.  The file name is formed from
+// the version and body, for example VSOP87A.emb.
+func LoadPlanetPathVersion(ibody int, v V87Version, path string) (*V87Planet, error) {
+	if v < 0 || v >= nVersions {
+		return nil, errors.New("Invalid version.")
+	}
+	if ibody < 0 || ibody >= nBodies {
 		return nil, errors.New("Invalid planet.")
 	}
-	data, err := ioutil.ReadFile(path + "/VSOP87B." + ext[ibody])
+	if !v.hasBody(ibody) {
+		return nil, fmt.Errorf("%s has no series for %s.",
+			v, strings.TrimSpace(b7[ibody]))
+	}
+	f, err := os.Open(filepath.Join(path, v.FileName(ibody)))
 	if err != nil {
 		return nil, err
 	}
-	v := &V87Planet{}
-	lines := strings.Split(string(data), "\n")
-	n := 0
-	n, err = v.l.parse('1', ibody, lines, n, false)
+	defer f.Close()
+	vt, err := LoadPlanetReader(ibody, f)
 	if err != nil {
 		return nil, err
 	}
-	n, err = v.b.parse('2', ibody, lines, n, false)
+	if vt.version != v {
+		return nil, fmt.Errorf("Expected %s, found %s.", v, vt.version)
+	}
+	return vt, nil
+}
+
+// LoadPlanetBytes constructs a V87Planet object from the contents of a
+// VSOP87 file.
+//
+// Argument ibody should be one of the body constants.  The version is
+// determined from the data and can be obtained with the Version method.
+// Data compressed with gzip is detected and decompressed.
+func LoadPlanetBytes(ibody int, data []byte) (*V87Planet, error) {
+	if ibody < 0 || ibody >= nBodies {
+		return nil, errors.New("Invalid planet.")
+	}
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		if data, err = ioutil.ReadAll(zr); err != nil {
+			return nil, err
+		}
+	}
+	return parseV87(ibody, string(data))
+}
+
+// LoadPlanetReader constructs a V87Planet object from VSOP87 file contents
+// read from r.
+//
+// Argument ibody should be one of the body constants.  As with
+// LoadPlanetBytes, the version is determined from the data and gzip
+// compression is detected.
+func LoadPlanetReader(ibody int, r io.Reader) (*V87Planet, error) {
+	data, err := ioutil.ReadAll(r)
 	if err != nil {
 		return nil, err
 	}
-	n, err = v.r.parse('3', ibody, lines, n, true)
+	return LoadPlanetBytes(ibody, data)
+}
+
+// LoadPlanetFS constructs a V87Planet object from the named VSOP87 file
+// of file system fsys.
+//
+// Argument ibody should be one of the body constants.  As with
+// LoadPlanetBytes, the version is determined from the data and gzip
+// compression is detected, so name may be for example "VSOP87B.ear.gz".
+func LoadPlanetFS(ibody int, fsys fs.FS, name string) (*V87Planet, error) {
+	f, err := fsys.Open(name)
 	if err != nil {
 		return nil, err
 	}
-	return v, nil
+	defer f.Close()
+	return LoadPlanetReader(ibody, f)
+}
+
+// parseV87 parses VSOP87 file data for body ibody.  The version is taken
+// from the first header line.
+func parseV87(ibody int, data string) (*V87Planet, error) {
+	lines := strings.Split(data, "\n")
+	if len(lines[0]) < 132 {
+		return nil, errors.New("Line 1: VSOP87 header expected.")
+	}
+	iv := V87Version(lines[0][17] - '0')
+	if iv < 0 || iv >= nVersions {
+		return nil, fmt.Errorf("Line 1: unknown version %c.", lines[0][17])
+	}
+	vt := &V87Planet{version: iv, body: ibody}
+	n := 0
+	var err error
+	for x := 0; x < iv.nVar(); x++ {
+		n, err = vt.series[x].parse(byte('1'+x), byte('0'+iv), ibody, lines, n)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return vt, nil
 }
 
-func (c *coeff) parse(ic byte, ibody int, lines []string, n int, au bool) (int, error) {
-	var cbuf [2047]abc
+func (c *coeff) parse(ic, iv byte, ibody int, lines []string, n int) (int, error) {
 	for n < len(lines) {
 		line := lines[n]
 		if len(line) < 132 {
@@ -115,35 +378,42 @@ func (c *coeff) parse(ic byte, ibody int, lines []string, n int, au bool) (int,
 		if line[41] != ic {
 			break
 		}
-		if iv := line[17]; iv != fileVersion {
+		if line[17] != iv {
 			return n, fmt.Errorf("Line %d: expected version %c, "+
-				"found %c.", n+1, fileVersion, iv)
+				"found %c.", n+1, iv, line[17])
 		}
 		if bo := line[22:29]; bo != b7[ibody] {
 			return n, fmt.Errorf("Line %d: expected body %s, "+
 				"found %s.", n+1, b7[ibody], bo)
 		}
 		it := line[59] - '0'
+		if it > 5 {
+			return n, fmt.Errorf("Line %d: invalid power of time %c.",
+				n+1, line[59])
+		}
 		in, err := strconv.Atoi(strings.TrimSpace(line[60:67]))
 		if err != nil {
 			return n, fmt.Errorf("Line %d: %v.", n+1, err)
 		}
+		n++
 		if in == 0 {
 			continue
 		}
 		if in > len(lines)-n {
 			return n, errors.New("Unexpected end of file.")
 		}
-		n++
-		cx := 0
-		for _, line := range lines[n : n+in] {
-			a := &cbuf[cx]
+		terms := make([]abc, in)
+		for cx, line := range lines[n : n+in] {
+			if len(line) < 131 {
+				return n, fmt.Errorf("Line %d: short line.", n+cx+1)
+			}
+			a := &terms[cx]
 			a.a, err =
 				strconv.ParseFloat(strings.TrimSpace(line[79:97]), 64)
 			if err != nil {
 				goto parseError
 			}
-			a.b, err = strconv.ParseFloat(line[98:111], 64)
+			a.b, err = strconv.ParseFloat(strings.TrimSpace(line[97:111]), 64)
 			if err != nil {
 				goto parseError
 			}
@@ -152,43 +422,338 @@ func (c *coeff) parse(ic byte, ibody int, lines []string, n int, au bool) (int,
 			if err != nil {
 				goto parseError
 			}
-			cx++
 			continue
 		parseError:
 			return n, fmt.Errorf("Line %d: %v.", n+cx+1, err)
 		}
-		c[it] = append([]abc{}, cbuf[:cx]...)
+		c[it] = terms
 		n += in
 	}
 	return n, nil
 }
 
+// sum evaluates series at τ, in Julian millennia from J2000.
+func (c *coeff) sum(τ float64) float64 {
+	var cf [6]float64
+	nt := 0
+	for x, terms := range c {
+		if len(terms) == 0 {
+			continue
+		}
+		// sum terms in reverse order to preserve accuracy
+		for y := len(terms) - 1; y >= 0; y-- {
+			term := &terms[y]
+			cf[x] += term.a * math.Cos(term.b+term.c*τ)
+		}
+		nt = x + 1
+	}
+	if nt == 0 {
+		return 0
+	}
+	return Horner(τ, cf[:nt]...)
+}
+
+// sumDot evaluates series and its derivative at τ, in Julian millennia
+// from J2000.  The derivative is per Julian millennium.
+func (c *coeff) sumDot(τ float64) (f, fd float64) {
+	var cf, cd [6]float64
+	nt := 0
+	for x, terms := range c {
+		if len(terms) == 0 {
+			continue
+		}
+		for y := len(terms) - 1; y >= 0; y-- {
+			term := &terms[y]
+			s, c := math.Sincos(term.b + term.c*τ)
+			cf[x] += term.a * c
+			cd[x] -= term.a * term.c * s
+		}
+		nt = x + 1
+	}
+	// d/dτ Σ τ^α S_α = Σ τ^α (Ṡ_α + (α+1) S_α+1)
+	for x := 0; x+1 < nt; x++ {
+		cd[x] += float64(x+1) * cf[x+1]
+	}
+	if nt == 0 {
+		return 0, 0
+	}
+	return Horner(τ, cf[:nt]...), Horner(τ, cd[:nt]...)
+}
+
 // Position2000 returns ecliptic position of planets by full VSOP87 theory.
 //
 // Argument jde is the date for which positions are desired.
 //
-// Results are for the dynamical equinox and ecliptic J2000.
+// Results are for the dynamical equinox and ecliptic J2000 for all
+// versions but V87C and V87D, which give results for the equinox and
+// ecliptic of date.  Results are heliocentric for all versions but V87E.
+// See Spherical.
 //
 //	L is heliocentric longitude in radians.
 //	B is heliocentric latitude in radians.
 //	R is heliocentric range in AU.
 func (vt *V87Planet) Position2000(jde float64) (L, B unit.Angle, R float64) {
-	T := J2000Century(jde)
-	τ := T * .1
-	cf := make([]float64, 6)
-	sum := func(series coeff) float64 {
-		for x, terms := range series {
-			cf[x] = 0
-			// sum terms in reverse order to preserve accuracy
-			for y := len(terms) - 1; y >= 0; y-- {
-				term := &terms[y]
-				cf[x] += term.a * math.Cos(term.b+term.c*τ)
-			}
+	return vt.Spherical(jde)
+}
+
+// Spherical returns spherical ecliptic coordinates of the body in the
+// frame of the loaded version.
+//
+// Argument jde is the date for which positions are desired.  Coordinates
+// are summed directly for versions V87B and V87D and converted for other
+// versions.
+//
+//	L is longitude in radians.
+//	B is latitude in radians.
+//	R is range in AU.
+func (vt *V87Planet) Spherical(jde float64) (L, B unit.Angle, R float64) {
+	return vt.spherical(J2000Century(jde) * .1)
+}
+
+// spherical returns Spherical for τ in Julian millennia from J2000.
+func (vt *V87Planet) spherical(τ float64) (L, B unit.Angle, R float64) {
+	if vt.version.Spherical() {
+		return unit.Angle(vt.series[0].sum(τ)).Mod1(),
+			unit.Angle(vt.series[1].sum(τ)),
+			vt.series[2].sum(τ)
+	}
+	p := vt.rectangular(τ)
+	R = math.Sqrt(p.Square())
+	L = unit.Angle(math.Atan2(p.Y, p.X)).Mod1()
+	B = unit.Angle(math.Asin(p.Z / R))
+	return
+}
+
+// Rectangular returns rectangular ecliptic coordinates of the body in the
+// frame of the loaded version, in AU.
+//
+// Argument jde is the date for which positions are desired.  Coordinates
+// are summed directly for versions V87A, V87C, and V87E and converted for
+// other versions.
+func (vt *V87Planet) Rectangular(jde float64) coord.Cart {
+	return vt.rectangular(J2000Century(jde) * .1)
+}
+
+// rectangular returns Rectangular for τ in Julian millennia from J2000.
+func (vt *V87Planet) rectangular(τ float64) coord.Cart {
+	switch {
+	case vt.version.Rectangular():
+		return coord.Cart{
+			X: vt.series[0].sum(τ),
+			Y: vt.series[1].sum(τ),
+			Z: vt.series[2].sum(τ),
 		}
-		return Horner(τ, cf[:len(series)]...)
+	case vt.version.Spherical():
+		L, B, R := vt.spherical(τ)
+		sL, cL := L.Sincos()
+		sB, cB := B.Sincos()
+		return coord.Cart{X: R * cB * cL, Y: R * cB * sL, Z: R * sB}
+	}
+	el := vt.elements(τ)
+	return el.rectangular()
+}
+
+// V87Elements holds the elliptic elements of the main version of VSOP87.
+//
+// Elements are heliocentric, referred to the dynamical ecliptic and
+// equinox J2000.
+type V87Elements struct {
+	A    float64    // semimajor axis, in AU
+	L    unit.Angle // mean longitude
+	K, H float64    // e cos ϖ, e sin ϖ
+	Q, P float64    // sin(i/2) cos Ω, sin(i/2) sin Ω
+}
+
+// Elliptic returns elliptic elements of the body.
+//
+// Argument jde is the date for which elements are desired.  Elements are
+// available only from series of version V87Elliptic; an error is returned
+// for other versions.
+func (vt *V87Planet) Elliptic(jde float64) (V87Elements, error) {
+	if vt.version != V87Elliptic {
+		return V87Elements{}, fmt.Errorf("Elements not available from %s.",
+			vt.version)
+	}
+	return vt.elements(J2000Century(jde) * .1), nil
+}
+
+func (vt *V87Planet) elements(τ float64) V87Elements {
+	return V87Elements{
+		A: vt.series[0].sum(τ),
+		L: unit.Angle(vt.series[1].sum(τ)).Mod1(),
+		K: vt.series[2].sum(τ),
+		H: vt.series[3].sum(τ),
+		Q: vt.series[4].sum(τ),
+		P: vt.series[5].sum(τ),
+	}
+}
+
+// eccentricLongitude solves Kepler's equation in the form
+// F - k sin F + h cos F = λ for eccentric longitude F.
+func (e *V87Elements) eccentricLongitude() float64 {
+	λ := e.L.Rad()
+	F := λ
+	for i := 0; i < 50; i++ {
+		sF, cF := math.Sincos(F)
+		d := (λ - F + e.K*sF - e.H*cF) / (1 - e.K*cF - e.H*sF)
+		F += d
+		if math.Abs(d) <= 1e-15 {
+			break
+		}
+	}
+	return F
+}
+
+// rectangular returns heliocentric rectangular coordinates from elements.
+func (e *V87Elements) rectangular() coord.Cart {
+	sF, cF := math.Sincos(e.eccentricLongitude())
+	ψ := 1 / (1 + math.Sqrt(1-e.K*e.K-e.H*e.H))
+	// coordinates in the orbital plane
+	x1 := e.A * ((1-ψ*e.H*e.H)*cF + ψ*e.H*e.K*sF - e.K)
+	y1 := e.A * ((1-ψ*e.K*e.K)*sF + ψ*e.H*e.K*cF - e.H)
+	// rotate to the ecliptic
+	g := 2 * math.Sqrt(1-e.P*e.P-e.Q*e.Q)
+	return coord.Cart{
+		X: (1-2*e.P*e.P)*x1 + 2*e.P*e.Q*y1,
+		Y: 2*e.P*e.Q*x1 + (1-2*e.Q*e.Q)*y1,
+		Z: g * (e.Q*y1 - e.P*x1),
+	}
+}
+
+// Velocities by analytic differentiation of the series.  Rates from the
+// series are per Julian millennium and are scaled to per day.
+
+const dayPerMillennium = 365250
+
+// SphericalVelocity returns rates of change of spherical ecliptic
+// coordinates of the body in the frame of the loaded version.
+//
+// Argument jde is the date for which rates are desired.  Rates are
+// computed by differentiating the series terms, for any version.
+//
+//	Ld is rate of longitude in radians per day.
+//	Bd is rate of latitude in radians per day.
+//	Rd is rate of range in AU per day.
+func (vt *V87Planet) SphericalVelocity(jde float64) (Ld, Bd unit.Angle, Rd float64) {
+	τ := J2000Century(jde) * .1
+	if vt.version.Spherical() {
+		_, ld := vt.series[0].sumDot(τ)
+		_, bd := vt.series[1].sumDot(τ)
+		_, rd := vt.series[2].sumDot(τ)
+		return unit.Angle(ld / dayPerMillennium),
+			unit.Angle(bd / dayPerMillennium), rd / dayPerMillennium
+	}
+	p, v := vt.State(jde)
+	ρ2 := p.X*p.X + p.Y*p.Y
+	R := math.Sqrt(ρ2 + p.Z*p.Z)
+	Rd = p.Dot(&v) / R
+	Ld = unit.Angle((p.X*v.Y - p.Y*v.X) / ρ2)
+	Bd = unit.Angle((v.Z*R - p.Z*Rd) / (R * math.Sqrt(ρ2)))
+	return
+}
+
+// State returns rectangular ecliptic position and velocity of the body
+// in the frame of the loaded version.
+//
+// Argument jde is the date for which the state is desired.  Position p is
+// in AU, velocity v is in AU/day.  Velocity is computed by differentiating
+// the series terms, for any version.
+func (vt *V87Planet) State(jde float64) (p, v coord.Cart) {
+	return vt.state(J2000Century(jde) * .1)
+}
+
+// state returns State for τ in Julian millennia from J2000.
+func (vt *V87Planet) state(τ float64) (p, v coord.Cart) {
+	p, v = vt.rectangularDot(τ)
+	v.MulScalar(&v, 1./dayPerMillennium)
+	return
+}
+
+// StateJ2000 returns rectangular equatorial position and velocity of the
+// body referenced to the FK5 J2000 frame.
+//
+// The rotation is that of SolarPositionJ2000, (26.3) p. 174, and applies
+// to the J2000 versions, V87Elliptic, V87A, V87B, and V87E.  An error is
+// returned for the versions of date, V87C and V87D.
+//
+// Position p is in AU, velocity v is in AU/day, as for
+// Orbit.PositionVelocity.
+func (vt *V87Planet) StateJ2000(jde float64) (p, v coord.Cart, err error) {
+	if vt.version.OfDate() {
+		return p, v, fmt.Errorf("%s is of date, not J2000.", vt.version)
+	}
+	p, v = vt.State(jde)
+	return vsopToFK5(p), vsopToFK5(v), nil
+}
+
+// rectangularDot returns rectangular position and rate per millennium.
+func (vt *V87Planet) rectangularDot(τ float64) (p, v coord.Cart) {
+	switch {
+	case vt.version.Rectangular():
+		p.X, v.X = vt.series[0].sumDot(τ)
+		p.Y, v.Y = vt.series[1].sumDot(τ)
+		p.Z, v.Z = vt.series[2].sumDot(τ)
+		return
+	case vt.version.Spherical():
+		L, Ld := vt.series[0].sumDot(τ)
+		B, Bd := vt.series[1].sumDot(τ)
+		R, Rd := vt.series[2].sumDot(τ)
+		sL, cL := math.Sincos(L)
+		sB, cB := math.Sincos(B)
+		p = coord.Cart{X: R * cB * cL, Y: R * cB * sL, Z: R * sB}
+		v = coord.Cart{
+			X: Rd*cB*cL - R*sB*cL*Bd - R*cB*sL*Ld,
+			Y: Rd*cB*sL - R*sB*sL*Bd + R*cB*cL*Ld,
+			Z: Rd*sB + R*cB*Bd,
+		}
+		return
+	}
+	var el, ed V87Elements
+	el.A, ed.A = vt.series[0].sumDot(τ)
+	var λ, λd float64
+	λ, λd = vt.series[1].sumDot(τ)
+	el.L, ed.L = unit.Angle(λ).Mod1(), unit.Angle(λd)
+	el.K, ed.K = vt.series[2].sumDot(τ)
+	el.H, ed.H = vt.series[3].sumDot(τ)
+	el.Q, ed.Q = vt.series[4].sumDot(τ)
+	el.P, ed.P = vt.series[5].sumDot(τ)
+	return el.rectangularDot(&ed)
+}
+
+// rectangularDot returns heliocentric rectangular position and velocity
+// from elements e and their rates ed.
+func (e *V87Elements) rectangularDot(ed *V87Elements) (p, v coord.Cart) {
+	a, k, h, q, pp := e.A, e.K, e.H, e.Q, e.P
+	F := e.eccentricLongitude()
+	sF, cF := math.Sincos(F)
+	// rate of F from F - k sin F + h cos F = λ
+	Fd := (ed.L.Rad() + ed.K*sF - ed.H*cF) / (1 - k*cF - h*sF)
+	s := math.Sqrt(1 - k*k - h*h)
+	ψ := 1 / (1 + s)
+	ψd := ψ * ψ * (k*ed.K + h*ed.H) / s
+	hk := ψ * h * k
+	hkd := ψd*h*k + ψ*ed.H*k + ψ*h*ed.K
+	u := (1-ψ*h*h)*cF + hk*sF - k
+	w := (1-ψ*k*k)*sF + hk*cF - h
+	ud := (-ψd*h*h-2*ψ*h*ed.H)*cF - (1-ψ*h*h)*sF*Fd +
+		hkd*sF + hk*cF*Fd - ed.K
+	wd := (-ψd*k*k-2*ψ*k*ed.K)*sF + (1-ψ*k*k)*cF*Fd +
+		hkd*cF - hk*sF*Fd - ed.H
+	x1, y1 := a*u, a*w
+	x1d, y1d := ed.A*u+a*ud, ed.A*w+a*wd
+	// rotate to the ecliptic
+	g := math.Sqrt(1 - pp*pp - q*q)
+	gd := -(pp*ed.P + q*ed.Q) / g
+	pq, pqd := pp*q, ed.P*q+pp*ed.Q
+	p = coord.Cart{
+		X: (1-2*pp*pp)*x1 + 2*pq*y1,
+		Y: 2*pq*x1 + (1-2*q*q)*y1,
+		Z: 2 * g * (q*y1 - pp*x1),
+	}
+	v = coord.Cart{
+		X: -4*pp*ed.P*x1 + (1-2*pp*pp)*x1d + 2*pqd*y1 + 2*pq*y1d,
+		Y: 2*pqd*x1 + 2*pq*x1d - 4*q*ed.Q*y1 + (1-2*q*q)*y1d,
+		Z: 2*gd*(q*y1-pp*x1) + 2*g*(ed.Q*y1+q*y1d-ed.P*x1-pp*x1d),
 	}
-	L = unit.Angle(sum(vt.l)).Mod1()
-	B = unit.Angle(sum(vt.b))
-	R = sum(vt.r)
 	return
 }
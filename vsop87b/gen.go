@@ -0,0 +1,57 @@
+// Public domain
+
+//go:build ignore
+// +build ignore
+
+// Gen writes gzip compressed copies of the VSOP87B files to directory data,
+// for embedding in package vsop87b.
+//
+// The directory containing the VSOP87B files must be indicated by
+// environment variable VSOP87.
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/soniakeys/astro"
+)
+
+func main() {
+	dir := os.Getenv("VSOP87")
+	if dir == "" {
+		log.Fatal("No path assigned to environment variable VSOP87")
+	}
+	for ibody := astro.Mercury; ibody <= astro.Neptune; ibody++ {
+		name := astro.V87B.FileName(ibody)
+		data, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			log.Fatal(err)
+		}
+		// validate before writing
+		if _, err := astro.LoadPlanetBytes(ibody, data); err != nil {
+			log.Fatal(name, ": ", err)
+		}
+		var b bytes.Buffer
+		z, err := gzip.NewWriterLevel(&b, gzip.BestCompression)
+		if err != nil {
+			log.Fatal(err)
+		}
+		z.Name = name
+		if _, err = z.Write(data); err != nil {
+			log.Fatal(err)
+		}
+		if err = z.Close(); err != nil {
+			log.Fatal(err)
+		}
+		err = ioutil.WriteFile(filepath.Join("data", name+".gz"), b.Bytes(),
+			0666)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+// Public domain
+
+// Package vsop87b provides VSOP87B series compiled into the program, so that
+// planet positions can be computed without access to the VSOP87 files.
+//
+// The series are embedded from gzip compressed copies of the original
+// VSOP87B files in directory data, written by gen.go.  To regenerate them,
+// set environment variable VSOP87 to the directory containing the files and
+// run go generate in this directory.
+package vsop87b
+
+//go:generate go run gen.go
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+
+	"github.com/soniakeys/astro"
+)
+
+//go:embed data
+var data embed.FS
+
+// path returns the embedded file name for ibody.
+func path(ibody int) string {
+	return "data/" + astro.V87B.FileName(ibody) + ".gz"
+}
+
+// LoadPlanet constructs an astro.V87Planet object from the embedded
+// VSOP87B series.
+//
+// Argument ibody should be one of the astro planet constants, Mercury
+// through Neptune.  No file system access is needed.
+func LoadPlanet(ibody int) (*astro.V87Planet, error) {
+	if !Available(ibody) {
+		return nil, fmt.Errorf("No embedded VSOP87B series for body %d.",
+			ibody)
+	}
+	b, err := data.ReadFile(path(ibody))
+	if err != nil {
+		return nil, err
+	}
+	return astro.LoadPlanetBytes(ibody, b)
+}
+
+// Available reports whether embedded series are present for ibody.
+func Available(ibody int) bool {
+	if ibody < astro.Mercury || ibody > astro.Neptune {
+		return false
+	}
+	_, err := fs.Stat(data, path(ibody))
+	return err == nil
+}
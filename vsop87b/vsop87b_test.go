@@ -0,0 +1,37 @@
+// Public domain.
+
+package vsop87b_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/astro/vsop87b"
+)
+
+func TestLoadPlanet(t *testing.T) {
+	if !vsop87b.Available(astro.Mars) {
+		t.Skip("VSOP87B data not present in directory data")
+	}
+	p, err := vsop87b.LoadPlanet(astro.Mars)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Version() != astro.V87B {
+		t.Fatal("version", p.Version())
+	}
+	// Mars 1899 spherical data from vsop87.chk.
+	l, b, r := p.Position2000(2415020.0)
+	if math.Abs(l.Rad()-5.0185792656) > 1e-10 ||
+		math.Abs(b.Rad()+0.0274073500) > 1e-10 ||
+		math.Abs(r-1.4218777718) > 1e-10 {
+		t.Errorf("got %.10f %.10f %.10f", l.Rad(), b.Rad(), r)
+	}
+}
+
+func TestLoadPlanetMissing(t *testing.T) {
+	if _, err := vsop87b.LoadPlanet(astro.Sun); err == nil {
+		t.Error("Sun: expected error")
+	}
+}
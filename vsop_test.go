@@ -0,0 +1,410 @@
+// Public domain.
+
+package astro_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/soniakeys/astro"
+	"github.com/soniakeys/coord"
+	"github.com/soniakeys/unit"
+)
+
+var b7 = []string{"MERCURY", "VENUS", "EARTH", "MARS", "JUPITER", "SATURN",
+	"URANUS", "NEPTUNE", "EMB", "SUN"}
+
+// v87Text formats series in the layout of VSOP87 files.  series is indexed
+// by variable, then power of time, then term; each term is A, B, C.
+func v87Text(v astro.V87Version, ibody int, series [][][][3]float64) string {
+	var sb strings.Builder
+	letter := ' '
+	if v > 0 {
+		letter = rune('A' + v - 1)
+	}
+	coords := "ELL"
+	switch {
+	case v.Rectangular():
+		coords = "XYZ"
+	case v.Spherical():
+		coords = "LBR"
+	}
+	for iv, powers := range series {
+		for it, terms := range powers {
+			h := fmt.Sprintf(" VSOP87 VERSION %c%d    %-7s   VARIABLE %d "+
+				"(%s)       *T**%d%7d TERMS    SYNTHETIC TEST SERIES",
+				letter, int(v), b7[ibody], iv+1, coords, it, len(terms))
+			fmt.Fprintf(&sb, "%-132s\n", h)
+			for n, t := range terms {
+				fmt.Fprintf(&sb, " %d%d%d%d%5d%36s%15.11f%18.11f"+
+					"%18.11f%14.11f%20.11f\n",
+					int(v), ibody%10, iv+1, it, n+1, "", 0., 0.,
+					t[0], t[1], t[2])
+			}
+		}
+	}
+	return sb.String()
+}
+
+// writeV87 writes series to a file in dir named as LoadPlanetPathVersion
+// expects.
+func writeV87(t *testing.T, dir string, v astro.V87Version, ibody int,
+	series [][][][3]float64) {
+	ext := strings.ToLower(b7[ibody][:3])
+	err := os.WriteFile(filepath.Join(dir, v.String()+"."+ext),
+		[]byte(v87Text(v, ibody, series)), 0666)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func ExampleV87Version() {
+	for v := astro.V87Elliptic; v <= astro.V87E; v++ {
+		fmt.Println(v, v.Rectangular(), v.Barycentric(), v.OfDate())
+	}
+	// Output:
+	// VSOP87 false false false
+	// VSOP87A true false false
+	// VSOP87B false false false
+	// VSOP87C true false true
+	// VSOP87D false false true
+	// VSOP87E true true false
+}
+
+func ExampleV87Version_FileName() {
+	fmt.Println(astro.V87A.FileName(astro.EMB))
+	fmt.Println(astro.V87B.FileName(astro.Neptune))
+	// Output:
+	// VSOP87A.emb
+	// VSOP87B.nep
+}
+
+func TestLoadPlanetVersion(t *testing.T) {
+	dir := t.TempDir()
+	// a circular orbit of radius 1.2 AU inclined to the ecliptic
+	writeV87(t, dir, astro.V87A, astro.EMB, [][][][3]float64{
+		{{{1.2, 0, 6000}}},
+		{{{1.2, -math.Pi / 2, 6000}}, {}, {{.01, 0, 0}}},
+		{{{.1, -math.Pi / 2, 6000}}},
+	})
+	vt, err := astro.LoadPlanetPathVersion(astro.EMB, astro.V87A, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vt.Version() != astro.V87A || vt.Body() != astro.EMB {
+		t.Fatal("Version, Body = ", vt.Version(), vt.Body())
+	}
+	jde := 2451545 + 1234.5
+	τ := 1234.5 / 365250
+	p := vt.Rectangular(jde)
+	sc, cc := math.Sincos(6000 * τ)
+	want := []float64{1.2 * cc, 1.2*sc + .01*τ*τ, .1 * sc}
+	for i, got := range []float64{p.X, p.Y, p.Z} {
+		if math.Abs(got-want[i]) > 1e-11 {
+			t.Errorf("Rectangular[%d] = %.12f, want %.12f", i, got, want[i])
+		}
+	}
+	L, B, R := vt.Spherical(jde)
+	if math.Abs(R-math.Sqrt(p.Square())) > 1e-14 ||
+		math.Abs(L.Rad()-unit.Angle(math.Atan2(p.Y, p.X)).Mod1().Rad()) >
+			1e-14 ||
+		math.Abs(B.Sin()*R-p.Z) > 1e-14 {
+		t.Error("Spherical inconsistent with Rectangular")
+	}
+	if _, err := vt.Elliptic(jde); err == nil {
+		t.Error("Elliptic from VSOP87A: expected error")
+	}
+	// no such series
+	if _, err := astro.LoadPlanetPathVersion(astro.EMB, astro.V87B,
+		dir); err == nil {
+		t.Error("EMB in VSOP87B: expected error")
+	}
+	// file content disagreeing with file name
+	os.Rename(filepath.Join(dir, "VSOP87A.emb"),
+		filepath.Join(dir, "VSOP87.emb"))
+	if _, err := astro.LoadPlanetPathVersion(astro.EMB, astro.V87Elliptic,
+		dir); err == nil {
+		t.Error("version mismatch: expected error")
+	}
+}
+
+func TestV87Elliptic(t *testing.T) {
+	dir := t.TempDir()
+	const a, λ0, n, k, h, q, p = 1.5, 1, 2000, .1, .05, .02, .01
+	writeV87(t, dir, astro.V87Elliptic, astro.Mars, [][][][3]float64{
+		{{{a, 0, 0}}},
+		{{{λ0, 0, 0}}, {{n, 0, 0}}},
+		{{{k, 0, 0}}},
+		{{{h, 0, 0}}},
+		{{{q, 0, 0}}},
+		{{{p, 0, 0}}},
+	})
+	vt, err := astro.LoadPlanetPathVersion(astro.Mars, astro.V87Elliptic, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jde := 2451545 + 3000.
+	τ := 3000. / 365250
+	el, err := vt.Elliptic(jde)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if el.A != a || math.Abs(el.L.Rad()-math.Mod(λ0+n*τ, 2*math.Pi)) > 1e-12 {
+		t.Fatal("Elliptic:", el)
+	}
+	// independent solution through classical elements
+	e := math.Hypot(k, h)
+	ϖ := math.Atan2(h, k)
+	inc := 2 * math.Asin(math.Hypot(q, p))
+	Ω := math.Atan2(p, q)
+	E, _, _ := astro.KeplerNewton(e, unit.Angle(λ0+n*τ-ϖ))
+	ν := 2 * math.Atan(math.Sqrt((1+e)/(1-e))*math.Tan(E.Rad()/2))
+	r := a * (1 - e*math.Cos(E.Rad()))
+	su, cu := math.Sincos(ν + ϖ - Ω)
+	sΩ, cΩ := math.Sincos(Ω)
+	want := []float64{
+		r * (cΩ*cu - sΩ*su*math.Cos(inc)),
+		r * (sΩ*cu + cΩ*su*math.Cos(inc)),
+		r * su * math.Sin(inc),
+	}
+	pos := vt.Rectangular(jde)
+	for i, got := range []float64{pos.X, pos.Y, pos.Z} {
+		if math.Abs(got-want[i]) > 1e-13 {
+			t.Errorf("Rectangular[%d] = %.14f, want %.14f", i, got, want[i])
+		}
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	dir := t.TempDir()
+	// terms of decreasing amplitude at powers 0 and 1
+	var x0, x1 [][3]float64
+	for i := 0; i < 40; i++ {
+		a := math.Pow(2, -float64(i))
+		x0 = append(x0, [3]float64{a, float64(i), 100 * float64(i+1)})
+		x1 = append(x1, [3]float64{a / 10, float64(i), 37 * float64(i+1)})
+	}
+	writeV87(t, dir, astro.V87E, astro.Sun, [][][][3]float64{
+		{x0, x1}, {x0}, {x1},
+	})
+	vt, err := astro.LoadPlanetPathVersion(astro.Sun, astro.V87E, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := vt.Truncate(1e-6)
+	// 2^-20 < 1e-6 < 2^-19, 2^-17 < 1e-5 < 2^-16
+	if n := tr.Terms(); n != 20+17+20+17 {
+		t.Fatal("terms kept:", n)
+	}
+	if vt.Terms() != 160 {
+		t.Fatal("original modified")
+	}
+	jde := 2451545 + 200000.
+	for _, e := range vt.TruncationError(jde) {
+		if e != 0 {
+			t.Fatal("untruncated error estimate", e)
+		}
+	}
+	est := tr.TruncationError(jde)
+	p, pt := vt.Rectangular(jde), tr.Rectangular(jde)
+	for i, d := range []float64{p.X - pt.X, p.Y - pt.Y, p.Z - pt.Z} {
+		if d == 0 || math.Abs(d) > 2*est[i] {
+			t.Errorf("variable %d: error %g, estimate %g", i, d, est[i])
+		}
+	}
+	// truncating further accumulates the estimate
+	tr2 := tr.Truncate(1e-3)
+	if e2 := tr2.TruncationError(jde); e2[0] <= est[0] {
+		t.Error("estimate did not increase")
+	}
+}
+
+func TestV87Velocity(t *testing.T) {
+	dir := t.TempDir()
+	per := [][][3]float64{
+		{{.3, .1, 500}, {.02, 2, 3000}},
+		{{.01, 1, 200}},
+		{{.001, 0, 0}},
+	}
+	writeV87(t, dir, astro.V87A, astro.Venus, [][][][3]float64{
+		{{{.7, 0, 10213}}, per[1]}, {{{.7, -math.Pi / 2, 10213}}}, per,
+	})
+	writeV87(t, dir, astro.V87D, astro.Venus, [][][][3]float64{
+		{{{3, 0, 0}, {.01, 1, 400}}, {{10213, 0, 0}}}, per, {{{.72, 0, 0}}, per[0]},
+	})
+	writeV87(t, dir, astro.V87Elliptic, astro.Venus, [][][][3]float64{
+		{{{.72, 0, 0}, {.001, 1, 300}}},
+		{{{3, 0, 0}}, {{10213, 0, 0}}},
+		{{{.005, 0, 0}}, {{.002, 1, 0}}},
+		{{{.004, 0, 0}, {.001, 0, 700}}},
+		{{{.006, 0, 0}}, {{.001, 0, 0}}},
+		{{{.03, 0, 0}, {.001, 2, 100}}},
+	})
+	// jde and h exactly representable to limit roundoff.
+	jde := 2451545 + 5432.125
+	const h = 1. / 1024 // day
+	for _, v := range []astro.V87Version{
+		astro.V87A, astro.V87D, astro.V87Elliptic} {
+		vt, err := astro.LoadPlanetPathVersion(astro.Venus, v, dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p, vel := vt.State(jde)
+		p0, p1 := vt.Rectangular(jde-h), vt.Rectangular(jde+h)
+		if pr := vt.Rectangular(jde); math.Abs(math.Sqrt(p.Square())-
+			math.Sqrt(pr.Square())) > 1e-15 {
+			t.Errorf("%s State position differs from Rectangular", v)
+		}
+		var fd coord.Cart
+		fd.Sub(&p1, &p0)
+		fd.MulScalar(&fd, 1/(2*h))
+		fd.Sub(&fd, &vel)
+		// tolerance is consistent with the precision of longitudes
+		// of many revolutions.
+		if d := math.Sqrt(fd.Square()); d > 5e-11 {
+			t.Errorf("%s velocity differs from numerical by %g AU/day", v, d)
+		}
+		L0, B0, R0 := vt.Spherical(jde - h)
+		L1, B1, R1 := vt.Spherical(jde + h)
+		Ld, Bd, Rd := vt.SphericalVelocity(jde)
+		dL := math.Remainder((L1-L0).Rad(), 2*math.Pi) / (2 * h)
+		if math.Abs(dL-Ld.Rad()) > 5e-11 ||
+			math.Abs((B1-B0).Rad()/(2*h)-Bd.Rad()) > 5e-11 ||
+			math.Abs((R1-R0)/(2*h)-Rd) > 5e-11 {
+			t.Errorf("%s SphericalVelocity differs from numerical", v)
+		}
+	}
+}
+
+func TestStateJ2000(t *testing.T) {
+	dir := t.TempDir()
+	writeV87(t, dir, astro.V87B, astro.Earth, [][][][3]float64{
+		{{{1.75, 0, 0}}, {{6283.07585, 0, 0}}},
+		{{{2e-6, 1, 5000}}},
+		{{{1, 0, 0}, {.0167, 3, 6283.07585}}},
+	})
+	e, err := astro.LoadPlanetPath(astro.Earth, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jde := 2455000.5
+	p, _, err := e.StateJ2000(jde)
+	if err != nil {
+		t.Fatal(err)
+	}
+	x, y, z, _ := astro.SolarPositionJ2000(e, jde)
+	if math.Abs(p.X+x) > 1e-14 || math.Abs(p.Y+y) > 1e-14 ||
+		math.Abs(p.Z+z) > 1e-14 {
+		t.Error("StateJ2000 inconsistent with SolarPositionJ2000")
+	}
+	// series of date have no J2000 state
+	writeV87(t, dir, astro.V87D, astro.Earth, [][][][3]float64{
+		{{{1.75, 0, 0}}, {{6283.07585, 0, 0}}},
+		{{{2e-6, 1, 5000}}},
+		{{{1, 0, 0}}},
+	})
+	d, err := astro.LoadPlanetPathVersion(astro.Earth, astro.V87D, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := d.StateJ2000(jde); err == nil {
+		t.Error("V87D: expected error")
+	}
+	if p, _ := (astro.SolarJ2000Ephemeris{Earth: d}).State(jde); !math.IsNaN(p.X) {
+		t.Error("SolarJ2000Ephemeris V87D: expected NaN, got", p)
+	}
+}
+
+func TestLoadPlanetFS(t *testing.T) {
+	text := v87Text(astro.V87C, astro.Jupiter, [][][][3]float64{
+		{{{5.2, 0, 529.69}}}, {{{5.2, -math.Pi / 2, 529.69}}}, {{{.1, 0, 0}}},
+	})
+	var z bytes.Buffer
+	zw := gzip.NewWriter(&z)
+	zw.Write([]byte(text))
+	zw.Close()
+	fsys := fstest.MapFS{
+		"VSOP87C.jup":    {Data: []byte(text)},
+		"VSOP87C.jup.gz": {Data: z.Bytes()},
+	}
+	jde := 2460000.5
+	want := []float64{}
+	for _, name := range []string{"VSOP87C.jup", "VSOP87C.jup.gz"} {
+		vt, err := astro.LoadPlanetFS(astro.Jupiter, fsys, name)
+		if err != nil {
+			t.Fatal(name, err)
+		}
+		if vt.Version() != astro.V87C {
+			t.Fatal(name, "version", vt.Version())
+		}
+		p := vt.Rectangular(jde)
+		if len(want) == 0 {
+			want = []float64{p.X, p.Y, p.Z}
+		} else if p.X != want[0] || p.Y != want[1] || p.Z != want[2] {
+			t.Error(name, "differs")
+		}
+	}
+	vt, err := astro.LoadPlanetReader(astro.Jupiter, bytes.NewReader(z.Bytes()))
+	if err != nil || vt.Version() != astro.V87C {
+		t.Fatal("LoadPlanetReader", err)
+	}
+	// validation of body applies
+	if _, err := astro.LoadPlanetReader(astro.Saturn,
+		strings.NewReader(text)); err == nil {
+		t.Error("wrong body: expected error")
+	}
+}
+
+func TestMarshalBinary(t *testing.T) {
+	text := v87Text(astro.V87Elliptic, astro.EMB, [][][][3]float64{
+		{{{1, 0, 0}, {1e-7, 1, 300}}},
+		{{{1.75, 0, 0}}, {{6283.07585, 0, 0}}},
+		{{{.0167, 0, 0}, {2e-5, 2, 100}}},
+		{{{.003, 0, 0}}, {{-1e-4, 0, 0}}},
+		{{{1e-6, 0, 0}}},
+		{{{2e-6, 1, 50}}},
+	})
+	full, err := astro.LoadPlanetBytes(astro.EMB, []byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	jde := 2469000.5
+	for _, vt := range []*astro.V87Planet{full, full.Truncate(1e-6)} {
+		b, err := vt.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		var u astro.V87Planet
+		if err := u.UnmarshalBinary(b); err != nil {
+			t.Fatal(err)
+		}
+		if u.Version() != vt.Version() || u.Body() != vt.Body() ||
+			u.Terms() != vt.Terms() {
+			t.Fatal("header not preserved")
+		}
+		if p, q := u.Rectangular(jde), vt.Rectangular(jde); p != q {
+			t.Error("positions differ", p, q)
+		}
+		if e, f := u.TruncationError(jde), vt.TruncationError(jde); e[0] != f[0] {
+			t.Error("truncation estimate differs", e, f)
+		}
+		// corruption
+		for _, i := range []int{0, 5, len(b) / 2, len(b) - 1} {
+			c := append([]byte{}, b...)
+			c[i] ^= 4
+			if err := u.UnmarshalBinary(c); err == nil {
+				t.Error("corruption at", i, "not detected")
+			}
+		}
+		if err := u.UnmarshalBinary(b[:len(b)-10]); err == nil {
+			t.Error("short data not detected")
+		}
+	}
+}
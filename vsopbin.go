@@ -0,0 +1,140 @@
+// Public domain
+
+package astro
+
+// Binary serialization of V87Planet coefficients.
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"math"
+)
+
+// Binary format:
+//
+//	magic "V87P"
+//	format version, vsop version, body, number of variables: 1 byte each
+//	term counts: uint32 for each variable and power of τ
+//	truncation estimates: float64 for each variable and power of τ
+//	terms: float64 a, b, c for each term in order of variable and power
+//	checksum: CRC-32 (IEEE) of all preceding bytes, uint32
+//
+// Multi-byte values are little-endian.
+const (
+	binMagic   = "V87P"
+	binFormat  = 1
+	binHeader  = len(binMagic) + 4
+	binTrailer = 4
+)
+
+// MarshalBinary encodes vt in a compact binary form suitable for caching
+// parsed series.
+//
+// The form holds all terms and truncation estimates of vt and a checksum.
+// It implements encoding.BinaryMarshaler.
+func (vt *V87Planet) MarshalBinary() ([]byte, error) {
+	nv := vt.version.nVar()
+	n := binHeader + nv*6*(4+8) + vt.Terms()*24 + binTrailer
+	b := make([]byte, 0, n)
+	b = append(b, binMagic...)
+	b = append(b, binFormat, byte(vt.version), byte(vt.body), byte(nv))
+	le := binary.LittleEndian
+	var buf [8]byte
+	for x := 0; x < nv; x++ {
+		for _, terms := range vt.series[x] {
+			le.PutUint32(buf[:4], uint32(len(terms)))
+			b = append(b, buf[:4]...)
+		}
+	}
+	putFloat := func(f float64) {
+		le.PutUint64(buf[:], math.Float64bits(f))
+		b = append(b, buf[:]...)
+	}
+	for x := 0; x < nv; x++ {
+		for _, d := range vt.dropped[x] {
+			putFloat(d)
+		}
+	}
+	for x := 0; x < nv; x++ {
+		for _, terms := range vt.series[x] {
+			for _, t := range terms {
+				putFloat(t.a)
+				putFloat(t.b)
+				putFloat(t.c)
+			}
+		}
+	}
+	le.PutUint32(buf[:4], crc32.ChecksumIEEE(b))
+	return append(b, buf[:4]...), nil
+}
+
+// UnmarshalBinary decodes data produced by MarshalBinary, replacing the
+// contents of vt.
+//
+// An error is returned if data is not in the expected form or if the
+// checksum indicates corruption.  It implements
+// encoding.BinaryUnmarshaler.
+func (vt *V87Planet) UnmarshalBinary(data []byte) error {
+	if len(data) < binHeader+binTrailer ||
+		string(data[:len(binMagic)]) != binMagic {
+		return errors.New("Not V87Planet binary data.")
+	}
+	le := binary.LittleEndian
+	body := data[:len(data)-binTrailer]
+	if le.Uint32(data[len(body):]) != crc32.ChecksumIEEE(body) {
+		return errors.New("V87Planet binary data: checksum mismatch.")
+	}
+	h := data[len(binMagic):binHeader]
+	if h[0] != binFormat {
+		return fmt.Errorf("V87Planet binary data: unknown format %d.", h[0])
+	}
+	v := V87Version(h[1])
+	ibody := int(h[2])
+	if v < 0 || v >= nVersions || ibody >= nBodies || int(h[3]) != v.nVar() {
+		return errors.New("V87Planet binary data: invalid header.")
+	}
+	nv := v.nVar()
+	p := body[binHeader:]
+	if len(p) < nv*6*(4+8) {
+		return errors.New("V87Planet binary data: truncated.")
+	}
+	var counts [6][6]int
+	nt := 0
+	for x := 0; x < nv; x++ {
+		for α := range counts[x] {
+			counts[x][α] = int(le.Uint32(p))
+			nt += counts[x][α]
+			p = p[4:]
+		}
+	}
+	if len(p) != nv*6*8+nt*24 {
+		return errors.New("V87Planet binary data: length mismatch.")
+	}
+	float := func() float64 {
+		f := math.Float64frombits(le.Uint64(p))
+		p = p[8:]
+		return f
+	}
+	n := V87Planet{version: v, body: ibody}
+	for x := 0; x < nv; x++ {
+		for α := range n.dropped[x] {
+			n.dropped[x][α] = float()
+		}
+	}
+	for x := 0; x < nv; x++ {
+		for α, c := range counts[x] {
+			if c == 0 {
+				continue
+			}
+			terms := make([]abc, c)
+			for i := range terms {
+				terms[i] = abc{float(), float(), float()}
+			}
+			n.series[x][α] = terms
+		}
+	}
+	*vt = n
+	return nil
+}